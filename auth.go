@@ -0,0 +1,164 @@
+package rail
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultAPIKeyHeader is the default header used by NewHeaderAuth.
+	DefaultAPIKeyHeader = "X-API-Key"
+	// DefaultAPIKeyParam is the default query parameter used by NewQueryAuth.
+	DefaultAPIKeyParam = "apikey"
+
+	// redacted replaces API Keys redacted by RedactURL.
+	redacted = "REDACTED"
+)
+
+// NewHeaderAuth returns a new authenticator function which adds API Key to
+// requests in the 'header' header, instead of the URL path.
+//
+// If 'header' is empty, DefaultAPIKeyHeader is used. It is useful when the
+// API is fronted by a proxy which moves the key to the URL path.
+func NewHeaderAuth(APIKey, header string) func(Requester) Requester {
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	return RequestFunc(func(req *http.Request) error {
+		req.Header.Set(header, APIKey)
+		return nil
+	})
+}
+
+// NewQueryAuth returns a new authenticator function which adds API Key to
+// requests in the 'param' query parameter, instead of the URL path.
+//
+// If 'param' is empty, DefaultAPIKeyParam is used. The parameter name is
+// carried by the context of requests, see APIKeyParam.
+func NewQueryAuth(APIKey, param string) func(Requester) Requester {
+	if param == "" {
+		param = DefaultAPIKeyParam
+	}
+	return func(r Requester) Requester {
+		return queryAuthRequester{APIKey, param, r}
+	}
+}
+
+// keyParamKey is the context key of the query parameter holding the API
+// Key of a request.
+type keyParamKey struct{}
+
+// queryAuthRequester adds API Key to the requests of Requester in the
+// 'param' query parameter.
+type queryAuthRequester struct {
+	key, param string
+	r          Requester
+}
+
+func (qr queryAuthRequester) Request() (*http.Request, error) {
+	req, err := qr.r.Request()
+	if err != nil {
+		return req, err
+	}
+
+	q := req.URL.Query()
+	q.Set(qr.param, qr.key)
+	req.URL.RawQuery = q.Encode()
+	return req.WithContext(context.WithValue(req.Context(), keyParamKey{}, qr.param)), nil
+}
+
+func (qr queryAuthRequester) Unwrap() Requester { return qr.r }
+
+// APIKeyParam returns the name of the query parameter holding the API Key
+// of 'req', as set by NewQueryAuth. It returns DefaultAPIKeyParam if the
+// key isn't in the query.
+func APIKeyParam(req *http.Request) string {
+	if param, ok := req.Context().Value(keyParamKey{}).(string); ok {
+		return param
+	}
+	return DefaultAPIKeyParam
+}
+
+// RedactURL returns 'u' as a string with API Keys replaced by "REDACTED".
+//
+// It redacts the path segment following "apikey", as added by NewAuth, and
+// values of the DefaultAPIKeyParam and 'params' query parameters, as added
+// by NewQueryAuth. Use APIKeyParam to get the parameter of a request.
+func RedactURL(u *url.URL, params ...string) string {
+	if u == nil {
+		return ""
+	}
+	return redactURL(u, redacted, params...).String()
+}
+
+// redactURL returns a copy of 'u' with API Keys in the path and in the
+// DefaultAPIKeyParam and 'params' query parameters replaced by 'repl'.
+// If 'repl' is empty, API Keys are removed along with their path segment
+// or query parameter.
+func redactURL(u *url.URL, repl string, params ...string) *url.URL {
+	k := *u
+	k.Path = redactPath(u.Path, "apikey", repl)
+	k.RawPath = ""
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for _, name := range append(params, DefaultAPIKeyParam) {
+			if _, ok := q[name]; !ok {
+				continue
+			}
+			if repl == "" {
+				q.Del(name)
+			} else {
+				q.Set(name, repl)
+			}
+		}
+		k.RawQuery = q.Encode()
+	}
+	return &k
+}
+
+// redactPath replaces the segment following 'name' in 'p' by 'repl'.
+// If 'repl' is empty, both segments are removed.
+func redactPath(p, name, repl string) string {
+	segs := strings.Split(p, "/")
+	for i := 0; i < len(segs)-1; i++ {
+		if segs[i] != name {
+			continue
+		}
+		if repl == "" {
+			return strings.Join(append(segs[:i:i], segs[i+2:]...), "/")
+		}
+		segs[i+1] = repl
+		return strings.Join(segs, "/")
+	}
+	return p
+}
+
+// pathParam returns the segment following 'name' in 'p', like the PNR
+// number following "pnr" in "v2/pnr-status/pnr/2144287856". It returns
+// the empty string if 'p' has no such segment.
+func pathParam(p, name string) string {
+	segs := strings.Split(p, "/")
+	for i := 0; i < len(segs)-1; i++ {
+		if segs[i] == name {
+			return segs[i+1]
+		}
+	}
+	return ""
+}
+
+// redactError redacts API Keys from the URL of a *url.Error in 'err',
+// as returned by http.Client for request 'req'.
+func redactError(err error, req *http.Request) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		if u, perr := url.Parse(uerr.URL); perr == nil {
+			uerr.URL = RedactURL(u, APIKeyParam(req))
+		}
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+)
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		auth func(rail.Requester) rail.Requester
+
+		expectedURL    string
+		expectedHeader string
+	}{
+		{
+			auth:        rail.NewAuth("SECRET"),
+			expectedURL: "https://api.railwayapi.com/v2/pnr-status/pnr/2144287856/apikey/SECRET",
+		},
+		{
+			auth:           rail.NewHeaderAuth("SECRET", ""),
+			expectedURL:    "https://api.railwayapi.com/v2/pnr-status/pnr/2144287856",
+			expectedHeader: "SECRET",
+		},
+		{
+			auth:        rail.NewQueryAuth("SECRET", ""),
+			expectedURL: "https://api.railwayapi.com/v2/pnr-status/pnr/2144287856?apikey=SECRET",
+		},
+	}
+
+	for _, tt := range tests {
+		var req *http.Request
+		c := rail.Client{Auth: tt.auth}
+		c.HTTPClient = &http.Client{Transport: mockTransport(func(r *http.Request) (*http.Response, error) {
+			req = r
+			return mockBody(`{"response_code": 200}`)(r)
+		})}
+
+		if _, err := c.PNRStatus(context.Background(), 2144287856); err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+
+		if req.URL.String() != tt.expectedURL {
+			t.Fatalf("expected: `%s`, actual `%s`", tt.expectedURL, req.URL)
+		}
+		if h := req.Header.Get(rail.DefaultAPIKeyHeader); h != tt.expectedHeader {
+			t.Fatalf("expected header: `%s`, actual `%s`", tt.expectedHeader, h)
+		}
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		params   []string
+		expected string
+	}{
+		{
+			input:    "https://api.railwayapi.com/v2/live/train/12138/date/05-04-2018/apikey/SECRET",
+			expected: "https://api.railwayapi.com/v2/live/train/12138/date/05-04-2018/apikey/REDACTED",
+		},
+		{
+			input:    "https://api.railwayapi.com/v2/route/train/14311?apikey=SECRET&x=1",
+			expected: "https://api.railwayapi.com/v2/route/train/14311?apikey=REDACTED&x=1",
+		},
+		{
+			input:    "https://api.railwayapi.com/v2/route/train/14311?token=SECRET&x=1",
+			params:   []string{"token"},
+			expected: "https://api.railwayapi.com/v2/route/train/14311?token=REDACTED&x=1",
+		},
+		{
+			input:    "https://api.railwayapi.com/v2/route/train/14311?keyword=x&token=y",
+			expected: "https://api.railwayapi.com/v2/route/train/14311?keyword=x&token=y",
+		},
+		{
+			input:    "https://api.railwayapi.com/v2/route/train/14311",
+			expected: "https://api.railwayapi.com/v2/route/train/14311",
+		},
+	}
+
+	for i, tt := range tests {
+		u, _ := url.Parse(tt.input)
+		if output := rail.RedactURL(u, tt.params...); output != tt.expected {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.expected, output)
+		}
+	}
+}
+
+func TestErrAPIRedacted(t *testing.T) {
+	c := rail.NewClient("SECRET")
+	c.HTTPClient = &http.Client{Transport: mockTransport(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusForbidden, Request: r}, nil
+	})}
+
+	_, err := c.PNRStatus(context.Background(), 2144287856)
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Fatalf("expected redacted error, actual `%v`", err)
+	}
+
+	u, _ := url.Parse("http://0.0.0.0")
+	c.HTTPClient = &http.Client{}
+	c.BaseURL = u
+
+	_, err = c.PNRStatus(context.Background(), 2144287856)
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Fatalf("expected redacted error, actual `%v`", err)
+	}
+	// Query parameters set by NewQueryAuth are redacted whatever their name.
+	c.Auth = rail.NewQueryAuth("SECRET", "token")
+	_, err = c.PNRStatus(context.Background(), 2144287856)
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Fatalf("expected redacted error, actual `%v`", err)
+	}
+}
@@ -0,0 +1,281 @@
+package rail
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBreakerFailures is the default number of consecutive failures
+	// which open a CircuitBreaker.
+	DefaultBreakerFailures = 5
+	// DefaultBreakerCooldown is the default duration for which a
+	// CircuitBreaker stays open.
+	DefaultBreakerCooldown = 30 * time.Second
+	// DefaultBreakerWindow is the default number of recent calls used by
+	// CircuitBreaker to compute failure rate.
+	DefaultBreakerWindow = 20
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState uint8
+
+const (
+	// BreakerClosed allows all calls.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all calls until cooldown is over.
+	BreakerOpen
+	// BreakerHalfOpen allows a single trial call, which closes the breaker
+	// if it succeeds and opens it again if it fails.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen is returned by API calls when the client's CircuitBreaker
+// rejects the call.
+type ErrCircuitOpen struct {
+	// State is the state of the breaker.
+	State BreakerState
+	// Until is the time when an open breaker turns half-open.
+	Until time.Time
+}
+
+// Error implements the error interface.
+func (err ErrCircuitOpen) Error() string {
+	if err.State == BreakerHalfOpen {
+		return "rail: circuit breaker is half-open, trial call in flight"
+	}
+	return fmt.Sprintf("rail: circuit breaker is open until %s", err.Until.Format(time.RFC3339))
+}
+
+// Is reports whether target is an ErrCircuitOpen.
+func (err ErrCircuitOpen) Is(target error) bool {
+	_, ok := target.(ErrCircuitOpen)
+	return ok
+}
+
+// CircuitBreaker stops calls to a failing backend until it recovers.
+//
+// Breaker opens after Failures consecutive failures, or when the failure
+// rate of the last Window calls reaches FailureRate. It rejects calls for
+// Cooldown duration, then turns half-open, allowing a single trial call to
+// decide whether to close or open again.
+//
+// Its zero value is usable breaker using DefaultBreakerFailures and
+// DefaultBreakerCooldown, without failure rate threshold.
+// CircuitBreaker is safe for use by multiple go routines.
+type CircuitBreaker struct {
+	// Failures is the number of consecutive failures which open the breaker.
+	Failures int
+	// Cooldown is the duration for which the breaker stays open.
+	Cooldown time.Duration
+
+	// FailureRate is the failure rate, between 0 and 1, of the last Window
+	// calls which opens the breaker. Failure rate isn't checked if zero.
+	FailureRate float64
+	// Window is the number of recent calls used to compute failure rate.
+	// Failure rate is checked only once Window calls are made.
+	// If zero, DefaultBreakerWindow is used.
+	Window int
+
+	// OnStateChange is called on every state transition of the breaker.
+	OnStateChange func(from, to BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // consecutive failures
+	openedAt time.Time // time when breaker was opened
+	trial    bool      // whether a half-open trial call is in flight
+
+	window   []bool // results of recent calls, true if failed
+	next     int    // index in window for next result
+	windowed int    // number of failures in window
+}
+
+// transition is a state transition of CircuitBreaker.
+type transition struct{ from, to BreakerState }
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	changes := b.cool(time.Now(), nil)
+	state := b.state
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return state
+}
+
+// allow returns ErrCircuitOpen if the call isn't allowed. If it returns nil,
+// the result of the call must be reported using done or release.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	changes := b.cool(time.Now(), nil)
+
+	var err error
+	switch b.state {
+	case BreakerOpen:
+		err = ErrCircuitOpen{State: b.state, Until: b.openedAt.Add(b.cooldown())}
+	case BreakerHalfOpen:
+		if b.trial {
+			err = ErrCircuitOpen{State: b.state}
+		}
+		b.trial = true
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return err
+}
+
+// done reports the result of an allowed call.
+func (b *CircuitBreaker) done(failed bool) {
+	b.mu.Lock()
+	var changes []transition
+
+	b.trial = false
+	b.record(failed)
+	switch {
+	case !failed:
+		b.failures = 0
+		changes = b.set(BreakerClosed, changes)
+	case b.state == BreakerHalfOpen || b.tripped():
+		b.openedAt = time.Now()
+		changes = b.set(BreakerOpen, changes)
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+// release reports that an allowed call ended without a result,
+// like when its context was cancelled.
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record records result of a call. Must hold b.mu.
+func (b *CircuitBreaker) record(failed bool) {
+	if failed {
+		b.failures++
+	}
+
+	if b.FailureRate <= 0 {
+		return
+	}
+
+	size := b.Window
+	if size <= 0 {
+		size = DefaultBreakerWindow
+	}
+	if len(b.window) < size {
+		b.window = append(b.window, failed)
+	} else {
+		if b.window[b.next] {
+			b.windowed--
+		}
+		b.window[b.next] = failed
+		b.next = (b.next + 1) % size
+	}
+	if failed {
+		b.windowed++
+	}
+}
+
+// tripped reports whether failures reached thresholds to open the breaker.
+// Must hold b.mu.
+func (b *CircuitBreaker) tripped() bool {
+	max := b.Failures
+	if max <= 0 {
+		max = DefaultBreakerFailures
+	}
+	if b.failures >= max {
+		return true
+	}
+
+	size := b.Window
+	if size <= 0 {
+		size = DefaultBreakerWindow
+	}
+	return b.FailureRate > 0 && len(b.window) >= size &&
+		float64(b.windowed)/float64(len(b.window)) >= b.FailureRate
+}
+
+// cooldown returns the duration for which the breaker stays open.
+func (b *CircuitBreaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return DefaultBreakerCooldown
+	}
+	return b.Cooldown
+}
+
+// cool turns an open breaker half-open if cooldown is over. Must hold b.mu.
+func (b *CircuitBreaker) cool(now time.Time, changes []transition) []transition {
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.cooldown() {
+		changes = b.set(BreakerHalfOpen, changes)
+	}
+	return changes
+}
+
+// set sets the state of breaker, appending the transition to 'changes'.
+// Must hold b.mu.
+func (b *CircuitBreaker) set(to BreakerState, changes []transition) []transition {
+	if b.state == to {
+		return changes
+	}
+
+	if to != BreakerOpen {
+		// Start afresh, so old failures don't trip the breaker again.
+		b.window, b.next, b.windowed = b.window[:0], 0, 0
+	}
+	changes = append(changes, transition{b.state, to})
+	b.state = to
+	return changes
+}
+
+// notify calls OnStateChange for 'changes'. Must not hold b.mu.
+func (b *CircuitBreaker) notify(changes []transition) {
+	if b.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		b.OnStateChange(c.from, c.to)
+	}
+}
+
+// backendFailed reports whether 'err' indicates a failure of the backend,
+// rather than an answer to the query.
+func backendFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rerr ErrResponseCode
+	if errors.As(err, &rerr) {
+		switch rerr.Code {
+		case CodeServiceUnavailable, CodeInvalidAPIKey, CodeAccountExpired:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
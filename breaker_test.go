@@ -0,0 +1,162 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{
+		`{"response_code": 405}`,
+		`{"response_code": 405}`,
+		`{"response_code": 200}`,
+	}}
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+
+	b := &rail.CircuitBreaker{Failures: 1, Cooldown: 20 * time.Millisecond}
+	f := &rail.Fallback{}
+	f.Add(c, b)
+
+	if b.State() != rail.BreakerClosed {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerClosed, b.State())
+	}
+
+	f.TrainByNumber(context.Background(), 14311)
+	if b.State() != rail.BreakerOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerOpen, b.State())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if b.State() != rail.BreakerHalfOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerHalfOpen, b.State())
+	}
+
+	// Failed trial call opens the breaker again.
+	f.TrainByNumber(context.Background(), 14311)
+	if b.State() != rail.BreakerOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerOpen, b.State())
+	}
+
+	// Successful trial call closes the breaker.
+	time.Sleep(20 * time.Millisecond)
+	if _, err := f.TrainByNumber(context.Background(), 14311); err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+	if b.State() != rail.BreakerClosed {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerClosed, b.State())
+	}
+}
+
+func TestCircuitBreakerCancelled(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{`{"response_code": 405}`}}
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+
+	b := &rail.CircuitBreaker{Failures: 1, Cooldown: 20 * time.Millisecond}
+	f := &rail.Fallback{}
+	f.Add(c, b)
+
+	f.TrainByNumber(context.Background(), 14311)
+	time.Sleep(20 * time.Millisecond)
+
+	// Trial call cancelled by the caller neither closes nor opens the
+	// breaker, and allows another trial call.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f.TrainByNumber(ctx, 14311)
+	if b.State() != rail.BreakerHalfOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerHalfOpen, b.State())
+	}
+
+	f.TrainByNumber(context.Background(), 14311)
+	if b.State() != rail.BreakerOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerOpen, b.State())
+	}
+}
+
+func TestClientBreaker(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{
+		`{"response_code": 221}`,
+		`{"response_code": 405}`,
+		`{"response_code": 405}`,
+		`{"response_code": 200}`,
+	}}
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Breaker = &rail.CircuitBreaker{
+		Failures: 2,
+		Cooldown: 20 * time.Millisecond,
+		OnStateChange: func(from, to rail.BreakerState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	}
+
+	// Answers to the query aren't failures.
+	if _, err := c.PNRStatus(context.Background(), 1234567890); !errors.Is(err, rail.ErrInvalidPNR) {
+		t.Fatal("expected ErrInvalidPNR, got:", err)
+	}
+	c.PNRStatus(context.Background(), 1234567890)
+	c.PNRStatus(context.Background(), 1234567890)
+
+	_, err := c.PNRStatus(context.Background(), 1234567890)
+	if !errors.Is(err, rail.ErrCircuitOpen{}) {
+		t.Fatal("expected ErrCircuitOpen, got:", err)
+	}
+	if st.calls != 3 {
+		t.Fatalf("expected %d calls, actual %d", 3, st.calls)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := c.PNRStatus(context.Background(), 1234567890); err != nil {
+		t.Fatal("PNRStatus failed:", err)
+	}
+
+	expected := []string{"closed->open", "open->half-open", "half-open->closed"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected: %v, actual %v", expected, changes)
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	// Alternating failures never reach consecutive failures threshold.
+	st := &sequenceTransport{
+		statuses: []int{500, 200, 500, 200, 500, 200},
+		bodies: []string{
+			"", `{"response_code": 200}`,
+			"", `{"response_code": 200}`,
+			"", `{"response_code": 200}`,
+		},
+	}
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Breaker = &rail.CircuitBreaker{FailureRate: 0.5, Window: 3}
+
+	for i := 0; i < 2; i++ {
+		c.TrainByNumber(context.Background(), 14311)
+		if c.Breaker.State() != rail.BreakerClosed {
+			t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerClosed, c.Breaker.State())
+		}
+	}
+
+	c.TrainByNumber(context.Background(), 14311)
+	if c.Breaker.State() != rail.BreakerOpen {
+		t.Fatalf("expected: `%s`, actual `%s`", rail.BreakerOpen, c.Breaker.State())
+	}
+}
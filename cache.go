@@ -0,0 +1,216 @@
+package rail
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache stores response bodies of API requests.
+//
+// Implementations must be safe for use by multiple go routines.
+type Cache interface {
+	// Get returns the response body stored for 'key', if not expired.
+	Get(key string) (body []byte, ok bool)
+	// Set stores the response body for 'key' for duration 'ttl'.
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// Default cache durations used by DefaultCacheTTL.
+const (
+	// StaticCacheTTL is used for requests of data which rarely change,
+	// like train routes and station names.
+	StaticCacheTTL = 24 * time.Hour
+	// ScheduleCacheTTL is used for requests of schedules, fares and
+	// cancelled or rescheduled trains.
+	ScheduleCacheTTL = 15 * time.Minute
+	// LiveCacheTTL is used for requests of live data, like live train
+	// status and seat availability.
+	LiveCacheTTL = time.Minute
+)
+
+// DefaultCacheTTL returns the default duration for which response of 'r'
+// is cached.
+//
+// Responses of PNRStatusReq and unknown Requesters are not cached. See
+// ClassOf for Requesters wrapping another one.
+func DefaultCacheTTL(r Requester) time.Duration {
+	return classTTL(ClassOf(r))
+}
+
+// classTTL returns the default cache duration of endpoints of class 'ec'.
+func classTTL(ec EndpointClass) time.Duration {
+	switch ec {
+	case StaticEndpoint:
+		return StaticCacheTTL
+	case ScheduleEndpoint:
+		return ScheduleCacheTTL
+	case LiveEndpoint:
+		return LiveCacheTTL
+	default:
+		return 0
+	}
+}
+
+// cacheTTL returns the duration for which response to request 'req' of 'r'
+// is cached by the client.
+func (c Client) cacheTTL(r Requester, req *http.Request) time.Duration {
+	if c.Cache == nil {
+		return 0
+	}
+	if c.CacheTTL != nil {
+		return c.CacheTTL(r)
+	}
+	return classTTL(classOf(r, req))
+}
+
+// cacheKey returns the cache key for request 'req'.
+//
+// API Keys are removed from the key, so responses can be shared by
+// clients using different keys.
+func cacheKey(req *http.Request) string {
+	return redactURL(req.URL, "", APIKeyParam(req)).String()
+}
+
+// LRUCache is an in-memory Cache which evicts the least recently used
+// entries when full.
+//
+// LRUCache is safe for use by multiple go routines.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// NewLRUCache returns a new LRUCache holding up to 'size' entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get implements the Cache interface.
+func (lc *LRUCache) Get(key string) ([]byte, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	el, ok := lc.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expires) {
+		lc.ll.Remove(el)
+		delete(lc.items, key)
+		return nil, false
+	}
+
+	lc.ll.MoveToFront(el)
+	return e.body, true
+}
+
+// Set implements the Cache interface.
+func (lc *LRUCache) Set(key string, body []byte, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := lc.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.body, e.expires = body, expires
+		lc.ll.MoveToFront(el)
+		return
+	}
+
+	lc.items[key] = lc.ll.PushFront(&lruEntry{key, body, expires})
+	for lc.size > 0 && lc.ll.Len() > lc.size {
+		el := lc.ll.Back()
+		lc.ll.Remove(el)
+		delete(lc.items, el.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones
+// not yet evicted.
+func (lc *LRUCache) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.ll.Len()
+}
+
+// DiskCache is a Cache which stores entries as files in a directory.
+//
+// Errors reading or writing files are treated as cache misses.
+// DiskCache is safe for use by multiple go routines and processes.
+type DiskCache struct {
+	// Dir is the directory to store files in. It is created if missing.
+	Dir string
+}
+
+// Get implements the Cache interface.
+func (dc DiskCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(dc.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	// File holds expiry time in unix nanoseconds on first line, then body.
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(string(data[:i]), 10, 64)
+	if err != nil || time.Now().UnixNano() > expires {
+		os.Remove(dc.path(key))
+		return nil, false
+	}
+	return data[i+1:], true
+}
+
+// Set implements the Cache interface.
+func (dc DiskCache) Set(key string, body []byte, ttl time.Duration) {
+	if err := os.MkdirAll(dc.Dir, 0755); err != nil {
+		return
+	}
+
+	f, err := ioutil.TempFile(dc.Dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(strconv.FormatInt(time.Now().Add(ttl).UnixNano(), 10) + "\n")
+	if err == nil {
+		_, err = f.Write(body)
+	}
+	if cerr := f.Close(); err != nil || cerr != nil {
+		return
+	}
+
+	// Rename is atomic, so readers never see a partially written file.
+	os.Rename(f.Name(), dc.path(key))
+}
+
+// path returns the file path for 'key'.
+func (dc DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.Dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,205 @@
+package rail_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+)
+
+func TestClientCache(t *testing.T) {
+	var urls []string
+	transport := mockTransport(func(r *http.Request) (*http.Response, error) {
+		urls = append(urls, r.URL.String())
+		return mockBody(`{"response_code": 200, "debit": 1, "train": {"number": "14311"}}`)(r)
+	})
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: transport}
+	c.Cache = rail.NewLRUCache(10)
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.TrainByNumber(context.Background(), 14311)
+		if err != nil {
+			t.Fatal("TrainByNumber failed:", err)
+		}
+		if resp.Train == nil || resp.Train.Number != 14311 {
+			t.Fatal("invalid response")
+		}
+	}
+
+	// Cached responses are shared by clients using different API keys.
+	c.Auth = rail.NewAuth("OTHER_KEY")
+	if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+
+	if len(urls) != 1 {
+		t.Fatalf("expected 1 request, actual %d", len(urls))
+	}
+
+	// PNRStatus responses are never cached.
+	for i := 0; i < 2; i++ {
+		if _, err := c.PNRStatus(context.Background(), 2144287856); err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+	}
+
+	if len(urls) != 3 {
+		t.Fatalf("expected 3 requests, actual %d", len(urls))
+	}
+}
+
+// opaqueRequester wraps a Requester without implementing Unwrap.
+type opaqueRequester struct{ r rail.Requester }
+
+func (or opaqueRequester) Request() (*http.Request, error) { return or.r.Request() }
+
+func TestClientCacheMiddleware(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{`{"response_code": 200, "train": {"number": "14311"}}`}}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Cache = rail.NewLRUCache(10)
+	c.RequestMiddleware = []rail.RequestMiddleware{
+		func(r rail.Requester) rail.Requester { return opaqueRequester{r} },
+	}
+
+	// Responses are cached by the request path if the endpoint is unknown.
+	for i := 0; i < 2; i++ {
+		if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+			t.Fatal("TrainByNumber failed:", err)
+		}
+	}
+	if st.calls != 1 {
+		t.Fatalf("expected 1 call, actual %d", st.calls)
+	}
+}
+
+func TestClientCacheQuery(t *testing.T) {
+	var urls []string
+	transport := mockTransport(func(r *http.Request) (*http.Response, error) {
+		urls = append(urls, r.URL.String())
+		return mockBody(`{"response_code": 200}`)(r)
+	})
+
+	c := rail.Client{HTTPClient: &http.Client{Transport: transport}, Cache: rail.NewLRUCache(10)}
+	c.CacheTTL = func(rail.Requester) time.Duration { return time.Hour }
+	other := c
+	c.Auth = rail.NewQueryAuth("KEY1", "token")
+	other.Auth = rail.NewQueryAuth("KEY2", "token")
+
+	get := func(c rail.Client, query string) {
+		r := mockRequester(func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, "v2/route/train/14311?"+query, nil)
+		})
+		if err := c.Do(c.Auth(r), &rail.TrainRouteResp{}); err != nil {
+			t.Fatal("Do failed:", err)
+		}
+	}
+
+	// Responses are shared by clients using different API Keys, but not by
+	// requests with different parameters.
+	get(c, "monkey=1")
+	get(other, "monkey=1")
+	get(c, "monkey=2")
+
+	if len(urls) != 2 {
+		t.Fatalf("expected 2 requests, actual %v", urls)
+	}
+}
+
+func TestClientCacheError(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{
+		`{"response_code": 405}`,
+		`{"response_code": 200, "train": {"number": "14311"}}`,
+	}}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Cache = rail.NewLRUCache(10)
+
+	if _, err := c.TrainByNumber(context.Background(), 14311); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+	if st.calls != 2 {
+		t.Fatalf("expected 2 calls, actual %d", st.calls)
+	}
+}
+
+func TestDefaultCacheTTL(t *testing.T) {
+	tests := []struct {
+		input    rail.Requester
+		expected time.Duration
+	}{
+		{rail.TrainRouteReq{TrainNumber: 14311}, rail.StaticCacheTTL},
+		{rail.WithCtx(context.Background(), rail.SuggestStationReq{StationName: "bareilly"}), rail.StaticCacheTTL},
+		{rail.NewAuth("KEY")(rail.TrainFareReq{}), rail.ScheduleCacheTTL},
+		{rail.LiveTrainStatusReq{}, rail.LiveCacheTTL},
+		{rail.TrainArrivalsReq{}, rail.LiveCacheTTL},
+		{rail.PNRStatusReq{}, 0},
+		{mockRequester(nil), 0},
+	}
+
+	for _, tt := range tests {
+		if output := rail.DefaultCacheTTL(tt.input); output != tt.expected {
+			t.Fatalf("input `%T`: expected: %s, actual %s", tt.input, tt.expected, output)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := rail.NewLRUCache(2)
+
+	c.Set("a", []byte("A"), time.Hour)
+	c.Set("b", []byte("B"), time.Hour)
+	c.Get("a")
+	c.Set("c", []byte("C"), time.Hour)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if body, ok := c.Get("a"); !ok || string(body) != "A" {
+		t.Fatal("expected entry `a` to be cached")
+	}
+
+	c.Set("d", []byte("D"), -time.Second)
+	if _, ok := c.Get("d"); ok {
+		t.Fatal("expected expired entry to be missed")
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected 1 entry, actual %d", c.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rail")
+	if err != nil {
+		t.Fatal("TempDir failed:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := rail.DiskCache{Dir: dir + "/cache"}
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected missing entry to be missed")
+	}
+
+	c.Set("a", []byte(`{"response_code": 200}`), time.Hour)
+	if body, ok := c.Get("a"); !ok || !strings.Contains(string(body), "200") {
+		t.Fatal("expected entry `a` to be cached")
+	}
+
+	c.Set("b", []byte("B"), -time.Second)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected expired entry to be missed")
+	}
+}
@@ -1,14 +1,17 @@
 package rail
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,9 +29,14 @@ var (
 	ErrNoAuth = errors.New("rail: no authenticator in client")
 )
 
+var defaultBaseURL, _ = url.Parse(DefaultBaseURL)
+
 // Requester is implemented by any value that has a Request method.
 type Requester interface {
 	// Request should generate an HTTP request from parameters.
+	//
+	// Client resolves relative request URLs against its BaseURL.
+	// Absolute URLs are sent as is.
 	Request() (*http.Request, error)
 }
 
@@ -45,61 +53,411 @@ func WithCtx(ctx context.Context, r Requester) Requester {
 	if ctx == nil {
 		return r
 	}
-	return RequesterFunc(func() (*http.Request, error) {
-		req, err := r.Request()
-		return req.WithContext(ctx), err
-	})
+	return ctxRequester{ctx, r}
+}
+
+// ctxRequester applies ctx to the requests of Requester.
+type ctxRequester struct {
+	ctx context.Context
+	r   Requester
+}
+
+func (cr ctxRequester) Request() (*http.Request, error) {
+	req, err := cr.r.Request()
+	if err != nil {
+		return req, err
+	}
+	return req.WithContext(cr.ctx), nil
+}
+
+func (cr ctxRequester) Unwrap() Requester { return cr.r }
+
+// observer is implemented by Requesters which observe the results of the
+// requests they generate.
+type observer interface {
+	// observe is called with the response body of every request sent,
+	// and returns true if the request should be generated and sent again.
+	observe(req *http.Request, body []byte, err error) bool
+}
+
+// findObserver returns the first observer in the Requesters wrapped by 'r'.
+func findObserver(r Requester) observer {
+	for r != nil {
+		if o, ok := r.(observer); ok {
+			return o
+		}
+		u, ok := r.(interface{ Unwrap() Requester })
+		if !ok {
+			return nil
+		}
+		r = u.Unwrap()
+	}
+	return nil
+}
+
+// unwrap returns the innermost Requester wrapped by 'r'.
+//
+// Requesters wrapping another Requester, like the ones returned by WithCtx
+// and NewAuth, implement an Unwrap method returning the wrapped Requester.
+func unwrap(r Requester) Requester {
+	for {
+		u, ok := r.(interface{ Unwrap() Requester })
+		if !ok {
+			return r
+		}
+		r = u.Unwrap()
+	}
 }
 
 // Client is an railwayapi's HTTP REST API client instance.
 //
-// Its zero value is usable client that uses http.DefaultTransport.
+// Its zero value is usable client that uses a private http.Client, shared by
+// clients without an HTTPClient, with requests timing out after DefaultTimeout.
 // Client is safe for use by multiple go routines.
 type Client struct {
-	// BaseURL is the base URL of the API server.
+	// BaseURL is the base URL of the API server, DefaultBaseURL if nil.
+	//
+	// It may have a path prefix and query parameters, like a gateway
+	// mounted at https://gw.example.com/railwayapi/?tenant=x. Paths of
+	// requests are appended to its path, and its query parameters are
+	// added to requests not setting them.
 	BaseURL *url.URL
 	// User agent used when communicating with the API.
 	UserAgent string
 	// HTTPClient is a reusable http client instance.
 	HTTPClient *http.Client
 
+	// Timeouts holds the timeout of each attempt to send a request, per
+	// class of its endpoint. Requests of other classes time out after
+	// DefaultTimeout if HTTPClient is nil, else as set in HTTPClient.
+	Timeouts map[EndpointClass]time.Duration
+
 	// Auth holds authenticator function used to authenticate requests.
 	//
 	// Client methods uses Auth to add APIKey to requests.
 	// Use NewAuth(apikey) to generate a new authenticator.
 	Auth func(Requester) Requester
+
+	// Retry holds the policy used to retry failed requests.
+	//
+	// Requests are not retried if Retry is nil.
+	Retry *RetryPolicy
+
+	// Limiter limits the rate of requests sent by the client.
+	Limiter *RateLimiter
+	// Budget tracks credits debited by the API for requests sent by the
+	// client, failing requests once its daily limit is reached.
+	Budget *CreditBudget
+
+	// Breaker stops sending requests while the API is failing, returning
+	// ErrCircuitOpen instead.
+	//
+	// Network errors, ErrAPI, ErrServiceUnavailable, ErrInvalidAPIKey and
+	// ErrCreditsExhausted count as failures of the API.
+	// Requests are always sent if Breaker is nil.
+	Breaker *CircuitBreaker
+
+	// Cache stores successful responses of the client.
+	//
+	// Responses are not cached if Cache is nil.
+	Cache Cache
+	// CacheTTL returns the duration for which response of 'r' is cached.
+	// Responses with non-positive duration are not cached.
+	//
+	// If nil, DefaultCacheTTL is used.
+	CacheTTL func(r Requester) time.Duration
+
+	// Coalescer deduplicates concurrent identical requests of the client,
+	// sending them once and sharing the response.
+	//
+	// Requests are not deduplicated if Coalescer is nil.
+	Coalescer *Coalescer
+
+	// RequestMiddleware wrap the Requester passed to Do, in order.
+	// Later middleware see the requests generated by earlier ones.
+	RequestMiddleware []RequestMiddleware
+	// ResponseMiddleware are called in order with the response of every
+	// request made by Do.
+	ResponseMiddleware []ResponseMiddleware
+
+	// Tracer starts spans for calls of Do and the requests they send.
+	//
+	// Calls are not traced if Tracer is nil.
+	Tracer Tracer
+
+	// Logger logs every request sent by the client, with its endpoint, URL,
+	// attempt, duration, HTTP status, response_code and debit.
+	// API Keys and PNR numbers are redacted from the logs.
+	//
+	// Requests are not logged if Logger is nil.
+	Logger *slog.Logger
+	// LogLevel returns the level at which a request which returned 'err'
+	// is logged.
+	//
+	// If nil, DefaultLogLevel is used.
+	LogLevel func(err error) slog.Level
+	// Decode defines how unexpected values in responses are handled.
+	Decode DecodeMode
+
+	// Now returns the current time, on which times of TrainArrivals are
+	// anchored.
+	//
+	// If nil, time.Now is used.
+	Now func() time.Time
+
+	// Debug sets the raw body and HTTP details of responses in the Response
+	// embedded in the values unmarshalled by Do, and makes Do return
+	// DecodeError with the path and snippet of the offending value when
+	// unmarshalling fails.
+	//
+	// Response bodies may hold personal details, like in PNRStatusResp.
+	Debug bool
 }
 
 // Do sends the http.Request and unmarshalls the JSON response into 'intoPtr'.
-func (c Client) Do(r Requester, intoPtr interface{}) error {
+//
+// If the response_code in the response isn't 200, Do returns ErrResponseCode
+// after unmarshalling the response.
+//
+// Requests go through the following stages, in order:
+//
+//  1. RequestMiddleware wrap 'r', in order.
+//  2. If client has a Cache, a response stored in it is used, if not expired.
+//  3. If client has a Coalescer, concurrent identical requests share a
+//     single response.
+//  4. If client has a Retry policy, failed requests are generated and sent
+//     again as allowed by the policy. Budget, Limiter and Breaker are checked
+//     before every attempt.
+//  5. Response is unmarshalled into 'intoPtr'.
+//  6. ResponseMiddleware are called with the response, in order.
+//  7. If client has a Cache, successful response is stored in it.
+func (c Client) Do(r Requester, intoPtr interface{}) (err error) {
 	if r == nil {
 		return errors.New("requester is nil")
 	}
 
-	req, err := r.Request()
+	for _, m := range c.RequestMiddleware {
+		r = m(r)
+	}
+
+	req, err := c.request(r)
 	if err != nil {
-		return errors.Wrap(err, "generate HTTP request failed")
+		return c.respond(nil, intoPtr, err)
 	}
 
-	client := c.HTTPClient
-	if client == nil {
-		client = http.DefaultClient
-		client.Transport = http.DefaultTransport
-		client.Timeout = 15 * time.Second
+	ctx, span := c.tracer().Start(req.Context(), "rail."+endpoint(r), requestAttrs(r)...)
+	defer func() { span.End(err) }()
+	r, req = WithCtx(ctx, r), req.WithContext(ctx)
+
+	var key string
+	ttl := c.cacheTTL(r, req)
+	if ttl > 0 {
+		key = cacheKey(req)
+		if body, ok := c.Cache.Get(key); ok {
+			span.SetAttributes(Attr{AttrCacheHit, true}, Attr{AttrResponseCode, envelope(body).ResponseCode})
+			return c.respond(nil, intoPtr, c.unmarshal(body, intoPtr, nil, 0))
+		}
 	}
 
-	if c.BaseURL != nil {
-		req.URL.Scheme = c.BaseURL.Scheme
-		req.URL.Host = c.BaseURL.Host
+	start := time.Now()
+	rsp, body, err := c.coalesce(r, req)
+	d := time.Since(start)
+	span.SetAttributes(responseAttrs(rsp, body)...)
+	if rsp != nil {
+		// Response may be shared by coalesced requests, give each its own body.
+		cp := *rsp
+		cp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		rsp = &cp
+	}
+
+	if body == nil {
+		return c.respond(rsp, intoPtr, err)
+	}
+
+	if err := c.unmarshal(body, intoPtr, rsp, d); err != nil {
+		return c.respond(rsp, intoPtr, err)
+	}
+
+	if err = c.respond(rsp, intoPtr, err); err == nil && ttl > 0 {
+		c.Cache.Set(key, body, ttl)
+	}
+	return err
+}
+
+// unmarshal unmarshalls the response 'body' into 'intoPtr' as defined by
+// client's Decode mode, keeping 'body' to be replayed by MarshalJSON. In
+// Debug mode, it also sets the details of response 'rsp' taking duration 'd'.
+func (c Client) unmarshal(body []byte, intoPtr interface{}, rsp *http.Response, d time.Duration) error {
+	warnings, err := decode(body, intoPtr, c.Decode)
+	if r := embedded(intoPtr); r != nil {
+		r.wire = append(json.RawMessage(nil), body...)
+		if len(warnings) > 0 {
+			r.Warnings = warnings
+		}
+	}
+	if c.Debug {
+		debug(intoPtr, body, rsp, d)
+	}
+
+	if err != nil && (c.Debug || c.Decode == DecodeStrict) {
+		return decodeError(body, intoPtr, err)
+	}
+	return errors.Wrap(err, "UnmarshalJSON failed")
+}
+
+// respond calls client's ResponseMiddleware in order, returning the
+// resulting error.
+func (c Client) respond(rsp *http.Response, intoPtr interface{}, err error) error {
+	for _, m := range c.ResponseMiddleware {
+		err = m(rsp, intoPtr, err)
+	}
+	return err
+}
+
+// request generates an http.Request from 'r' for the client.
+func (c Client) request(r Requester) (*http.Request, error) {
+	req, err := r.Request()
+	if err != nil {
+		return nil, errors.Wrap(err, "generate HTTP request failed")
+	}
+
+	if !req.URL.IsAbs() {
+		base := c.BaseURL
+		if base == nil {
+			base = defaultBaseURL
+		}
+		req.URL = resolveURL(base, req.URL)
+		req.Host = req.URL.Host
 	}
 
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
+	return req, nil
+}
+
+// now returns the current time of the client.
+func (c Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// resolveURL resolves relative URL 'u' against 'base', appending the path
+// of 'u' to the path of 'base' and adding query parameters of 'base' not
+// set in 'u'.
+func resolveURL(base, u *url.URL) *url.URL {
+	r := *base
+	r.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.TrimPrefix(u.Path, "/")
+	r.RawPath = ""
+	r.Fragment = u.Fragment
+
+	q := base.Query()
+	for k, v := range u.Query() {
+		q[k] = v
+	}
+	r.RawQuery = q.Encode()
+	return &r
+}
+
+// send sends 'req', generating it again from 'r' for every retry
+// allowed by the client's Retry policy.
+//
+// It returns the last response and its body, along with ErrResponseCode
+// if the response_code in the body isn't 200.
+func (c Client) send(r Requester, req *http.Request) (*http.Response, []byte, error) {
+	o := findObserver(r)
+	timeout := c.timeout(r, req)
+	for attempt, sent := 1, 1; ; sent++ {
+		ctx, span := c.tracer().Start(req.Context(), SpanAttempt, Attr{AttrAttempt, sent})
+		start := time.Now()
+		rsp, body, err := c.do(req.WithContext(ctx), timeout)
+		c.log(r, req, sent, time.Since(start), rsp, body, err)
+		span.SetAttributes(responseAttrs(rsp, body)...)
+		span.End(err)
+
+		switch {
+		case o != nil && o.observe(req, body, err):
+			// Requester asked to send the request again, like KeyPool
+			// failing over to another API Key.
+		case c.Retry != nil && c.Retry.retry(attempt, err):
+			if werr := c.Retry.wait(req.Context(), attempt); werr != nil {
+				return rsp, nil, errors.Wrapf(werr, "retry aborted after %d attempts: %s", attempt, err)
+			}
+			attempt++
+		default:
+			return rsp, body, err
+		}
+
+		if req, err = c.request(r); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// do sends the http.Request once and returns the response and its body.
+// The attempt is aborted after 'timeout', if positive.
+//
+// Returned response's body is already read and closed.
+func (c Client) do(req *http.Request, timeout time.Duration) (rsp *http.Response, body []byte, err error) {
+	if c.Budget != nil {
+		if err := c.Budget.allow(); err != nil {
+			return nil, nil, err
+		}
+		defer func() {
+			var debit int
+			if body != nil {
+				debit = envelope(body).Debit
+			}
+			c.Budget.settle(debit)
+		}()
+	}
+
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(req.Context()); err != nil {
+			return nil, nil, errors.Wrap(err, "rate limit wait failed")
+		}
+	}
 
-	rsp, err := client.Do(req)
+	if c.Breaker == nil {
+		return c.roundTrip(req, timeout)
+	}
+
+	if err := c.Breaker.allow(); err != nil {
+		return nil, nil, err
+	}
+	rsp, body, err = c.roundTrip(req, timeout)
+	if req.Context().Err() != nil {
+		// Caller gave up, API isn't to blame.
+		c.Breaker.release()
+	} else {
+		c.Breaker.done(backendFailed(err))
+	}
+	return rsp, body, err
+}
+
+// roundTrip sends the http.Request and returns the response and its body.
+// The request is aborted after 'timeout', if positive.
+func (c Client) roundTrip(req *http.Request, timeout time.Duration) (*http.Response, []byte, error) {
+	ctx := req.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	timedOut := func(err error) error {
+		if req.Context().Err() == nil && ctx.Err() != nil {
+			// Only this attempt timed out, it may succeed if sent again.
+			return ErrTimeout{timeout}
+		}
+		return err
+	}
+
+	rsp, err := c.httpClient().Do(req.WithContext(ctx))
 	if err != nil {
-		return errors.Wrap(err, "HTTP request failed")
+		return nil, nil, errors.Wrap(redactError(timedOut(err), req), "HTTP request failed")
 	}
 
 	defer func() {
@@ -115,13 +473,24 @@ func (c Client) Do(r Requester, intoPtr interface{}) error {
 	}()
 
 	if rsp.StatusCode != http.StatusOK {
-		return ErrAPI{rsp}
+		return rsp, nil, ErrAPI{rsp}
+	}
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return rsp, nil, errors.Wrap(timedOut(err), "read response body failed")
 	}
 
-	return errors.Wrap(json.NewDecoder(rsp.Body).Decode(intoPtr), "UnmarshalJSON failed")
+	r := envelope(body)
+
+	// API responds with status code 200 even for failed requests,
+	// the actual status is in the response_code key of the body.
+	return rsp, body, r.err(req)
 }
 
 // ErrAPI is returned by API calls when the response status code isn't 200.
+//
+// API Key is redacted from the request URL in its error message.
 type ErrAPI struct {
 	// Response from the request which returned error.
 	Response *http.Response
@@ -132,7 +501,7 @@ func (err ErrAPI) Error() (errStr string) {
 	if err.Response != nil {
 		errStr += fmt.Sprintf(
 			"request to %s returned %d (%s)",
-			err.Response.Request.URL,
+			RedactURL(err.Response.Request.URL, APIKeyParam(err.Response.Request)),
 			err.Response.StatusCode,
 			http.StatusText(err.Response.StatusCode),
 		)
@@ -140,23 +509,57 @@ func (err ErrAPI) Error() (errStr string) {
 	return errStr
 }
 
+// Retryable reports whether the request may succeed if sent again.
+func (err ErrAPI) Retryable() bool {
+	if err.Response == nil {
+		return false
+	}
+	return err.Response.StatusCode >= http.StatusInternalServerError ||
+		err.Response.StatusCode == http.StatusTooManyRequests
+}
+
+// ErrTimeout is returned by API calls when an attempt to send a request
+// times out, as set by client's Timeouts.
+type ErrTimeout struct {
+	// Timeout is the timeout of the attempt.
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (err ErrTimeout) Error() string {
+	return fmt.Sprintf("rail: request timed out after %s", err.Timeout)
+}
+
+// Retryable reports whether the request may succeed if sent again.
+func (err ErrTimeout) Retryable() bool { return true }
+
 // NewAuth returns a new authenticator function.
 //
 // Assign to client.Auth field to make client methods use it for requests.
 func NewAuth(APIKey string) func(Requester) Requester {
 	return func(r Requester) Requester {
-		return RequesterFunc(func() (*http.Request, error) {
-			req, err := r.Request()
-			if err != nil {
-				return req, errors.Wrap(err, "generate HTTP request failed")
-			}
+		return authRequester{APIKey, r}
+	}
+}
+
+// authRequester adds API Key to the requests of Requester.
+type authRequester struct {
+	key string
+	r   Requester
+}
 
-			req.URL.Path = path.Join(req.URL.Path, fmt.Sprintf("/apikey/%s/", APIKey))
-			return req, nil
-		})
+func (ar authRequester) Request() (*http.Request, error) {
+	req, err := ar.r.Request()
+	if err != nil {
+		return req, errors.Wrap(err, "generate HTTP request failed")
 	}
+
+	req.URL.Path = path.Join(req.URL.Path, fmt.Sprintf("/apikey/%s/", ar.key))
+	return req, nil
 }
 
+func (ar authRequester) Unwrap() Requester { return ar.r }
+
 // NewClient returns a new RailwayAPI.com authenticated API client.
 //
 // Use returned client's methods to access various API functions.
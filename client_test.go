@@ -2,6 +2,7 @@ package rail_test
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -127,6 +128,56 @@ func TestClientDo(t *testing.T) {
 	}
 }
 
+func TestClientBaseURL(t *testing.T) {
+	tests := []struct {
+		inputBaseURL string
+		inputAuth    func(rail.Requester) rail.Requester
+		expected     string
+	}{
+		{
+			inputBaseURL: "",
+			inputAuth:    rail.NewAuth("KEY"),
+			expected:     "https://api.railwayapi.com/v2/name-number/train/14311/apikey/KEY",
+		},
+		{
+			inputBaseURL: "http://gw.example.com/railwayapi/",
+			inputAuth:    rail.NewAuth("KEY"),
+			expected:     "http://gw.example.com/railwayapi/v2/name-number/train/14311/apikey/KEY",
+		},
+		{
+			inputBaseURL: "http://gw.example.com/railwayapi?tenant=x",
+			inputAuth:    rail.NewAuth("KEY"),
+			expected:     "http://gw.example.com/railwayapi/v2/name-number/train/14311/apikey/KEY?tenant=x",
+		},
+		{
+			inputBaseURL: "http://gw.example.com/railwayapi/?apikey=BASE&tenant=x",
+			inputAuth:    rail.NewQueryAuth("KEY", ""),
+			expected:     "http://gw.example.com/railwayapi/v2/name-number/train/14311?apikey=KEY&tenant=x",
+		},
+	}
+
+	for i, tt := range tests {
+		var actual string
+		c := rail.Client{Auth: tt.inputAuth}
+		c.HTTPClient = &http.Client{Transport: mockTransport(
+			func(r *http.Request) (*http.Response, error) {
+				actual = r.URL.String()
+				return mockBody(`{"response_code": 200}`)(r)
+			},
+		)}
+		if tt.inputBaseURL != "" {
+			c.BaseURL, _ = url.Parse(tt.inputBaseURL)
+		}
+
+		if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+			t.Fatalf("%d. TrainByNumber failed: %s", i, err)
+		}
+		if actual != tt.expected {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.expected, actual)
+		}
+	}
+}
+
 // mockRequester mocks Requester and helps in testing.
 type mockRequester func() (*http.Request, error)
 
@@ -136,3 +187,14 @@ func (mr mockRequester) Request() (*http.Request, error) { return mr() }
 type mockTransport func(*http.Request) (*http.Response, error)
 
 func (mt mockTransport) RoundTrip(r *http.Request) (*http.Response, error) { return mt(r) }
+
+// mockBody returns a mockTransport which responds with 'body' to every request.
+func mockBody(body string) mockTransport {
+	return func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	}
+}
@@ -0,0 +1,113 @@
+package rail
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Coalescer deduplicates concurrent identical requests.
+//
+// Requests for the same URL with the same headers made while one is in
+// flight wait for it and share its response, instead of being sent again.
+// Each waiter unmarshalls the shared response into its own value.
+//
+// A waiter whose context is done returns early without affecting others.
+// The shared request is cancelled only when all of its waiters are gone.
+//
+// Its zero value is ready to use.
+// Coalescer is safe for use by multiple go routines.
+type Coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is an in-flight or completed request of Coalescer.
+type call struct {
+	done    chan struct{}
+	rsp     *http.Response
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls 'fn' once for concurrent calls with same 'key', returning
+// its results to all of them.
+//
+// 'fn' is called with a context which is done when all the waiters' contexts
+// are done. It holds the values of 'ctx' of the first caller.
+func (co *Coalescer) do(ctx context.Context, key string,
+	fn func(context.Context) (*http.Response, []byte, error),
+) (*http.Response, []byte, error) {
+	co.mu.Lock()
+	if co.calls == nil {
+		co.calls = make(map[string]*call)
+	}
+
+	cl, ok := co.calls[key]
+	if !ok {
+		cctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		co.calls[key] = cl
+
+		go func() {
+			cl.rsp, cl.body, cl.err = fn(cctx)
+
+			co.mu.Lock()
+			if co.calls[key] == cl {
+				delete(co.calls, key)
+			}
+			co.mu.Unlock()
+
+			cancel()
+			close(cl.done)
+		}()
+	}
+	cl.waiters++
+	co.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.rsp, cl.body, cl.err
+	case <-ctx.Done():
+		co.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			// Nobody is waiting for the response anymore.
+			cl.cancel()
+			if co.calls[key] == cl {
+				delete(co.calls, key)
+			}
+		}
+		co.mu.Unlock()
+		return nil, nil, errors.Wrap(ctx.Err(), "coalesced request aborted")
+	}
+}
+
+// coalesce sends 'req' using send, sharing the response with concurrent
+// identical requests if client has a Coalescer.
+func (c Client) coalesce(r Requester, req *http.Request) (*http.Response, []byte, error) {
+	if c.Coalescer == nil {
+		return c.send(r, req)
+	}
+
+	return c.Coalescer.do(req.Context(), coalesceKey(req),
+		func(ctx context.Context) (*http.Response, []byte, error) {
+			return c.send(WithCtx(ctx, r), req.WithContext(ctx))
+		},
+	)
+}
+
+// coalesceKey returns the key identifying requests identical to 'req', by
+// method, URL and a hash of headers, which may hold API Keys added by
+// NewHeaderAuth.
+func coalesceKey(req *http.Request) string {
+	h := sha256.New()
+	req.Header.Write(h)
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(h.Sum(nil))
+}
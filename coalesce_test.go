@@ -0,0 +1,164 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+// blockingTransport responds to requests once released, counting them.
+type blockingTransport struct {
+	release chan struct{}
+	calls   int32
+	ctxErr  chan error
+}
+
+func (bt *blockingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&bt.calls, 1)
+
+	select {
+	case <-bt.release:
+		return mockBody(`{"response_code": 200, "debit": 1, "train": {"number": "12138"}}`)(r)
+	case <-r.Context().Done():
+		if bt.ctxErr != nil {
+			bt.ctxErr <- r.Context().Err()
+		}
+		return nil, r.Context().Err()
+	}
+}
+
+func TestCoalescer(t *testing.T) {
+	bt := &blockingTransport{release: make(chan struct{})}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: bt}
+	c.Coalescer = &rail.Coalescer{}
+
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.LiveTrainStatus(context.Background(), 12138, d)
+			if err == nil && (resp.Train == nil || resp.Train.Number != 12138) {
+				err = errors.New("invalid response")
+			}
+			errs <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(bt.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal("LiveTrainStatus failed:", err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&bt.calls); calls != 1 {
+		t.Fatalf("expected 1 call, actual %d", calls)
+	}
+}
+
+func TestCoalescerCancel(t *testing.T) {
+	bt := &blockingTransport{release: make(chan struct{}), ctxErr: make(chan error, 1)}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: bt}
+	c.Coalescer = &rail.Coalescer{}
+
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, time.UTC)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.LiveTrainStatus(ctx1, 12138, d)
+		errs <- err
+	}()
+	go func() {
+		_, err := c.LiveTrainStatus(ctx2, 12138, d)
+		errs <- err
+	}()
+
+	// Cancelling one waiter doesn't cancel the shared request.
+	time.Sleep(50 * time.Millisecond)
+	cancel1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: `%v`, actual `%v`", context.Canceled, err)
+	}
+
+	close(bt.release)
+	if err := <-errs; err != nil {
+		t.Fatal("LiveTrainStatus failed:", err)
+	}
+
+	// Cancelling all waiters cancels the shared request.
+	bt.release = make(chan struct{})
+	ctx3, cancel3 := context.WithCancel(context.Background())
+	go func() {
+		_, err := c.LiveTrainStatus(ctx3, 12138, d)
+		errs <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel3()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: `%v`, actual `%v`", context.Canceled, err)
+	}
+
+	select {
+	case err := <-bt.ctxErr:
+		if err != context.Canceled {
+			t.Fatalf("expected: `%v`, actual `%v`", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected shared request to be cancelled")
+	}
+}
+
+func TestCoalescerHeaderAuth(t *testing.T) {
+	bt := &blockingTransport{release: make(chan struct{})}
+
+	c := rail.Client{HTTPClient: &http.Client{Transport: bt}, Coalescer: &rail.Coalescer{}}
+	c.Auth = rail.NewHeaderAuth("KEY1", "")
+	other := c
+	other.Auth = rail.NewHeaderAuth("KEY2", "")
+
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, time.UTC)
+
+	// Requests with different API Keys in headers aren't shared.
+	errs := make(chan error, 4)
+	for _, cl := range []rail.Client{c, c, other, other} {
+		go func(cl rail.Client) {
+			_, err := cl.LiveTrainStatus(context.Background(), 12138, d)
+			errs <- err
+		}(cl)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(bt.release)
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal("LiveTrainStatus failed:", err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&bt.calls); calls != 2 {
+		t.Fatalf("expected 2 calls, actual %d", calls)
+	}
+}
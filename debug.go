@@ -0,0 +1,313 @@
+package rail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// snippetSize is the maximum size of DecodeError snippets.
+const snippetSize = 128
+
+// HTTPMetadata holds details of the HTTP response of a Response.
+type HTTPMetadata struct {
+	// URL is the request URL, with API Key redacted.
+	URL string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header is the header of the response.
+	Header http.Header
+	// Duration is the duration of the request, including retries.
+	Duration time.Duration
+}
+
+// DecodeError is returned by Client.Do in Debug or DecodeStrict mode when
+// the response body can't be unmarshalled.
+type DecodeError struct {
+	// Path is the path of the offending value in the body, like
+	// "route[2].station". It is empty if the body itself is invalid.
+	Path string
+	// Offset is the offset of the offending value in the body.
+	Offset int64
+	// Snippet is the body around the offending value, truncated if large.
+	Snippet string
+	// Err is the error returned by the unmarshaller.
+	Err error
+}
+
+// Error implements the error interface.
+func (err DecodeError) Error() string {
+	path := err.Path
+	if path == "" {
+		path = "."
+	}
+	return fmt.Sprintf("UnmarshalJSON failed at %s (offset %d, near %q): %s",
+		path, err.Offset, err.Snippet, err.Err)
+}
+
+// Cause returns the error returned by the unmarshaller.
+func (err DecodeError) Cause() error { return err.Err }
+
+// Unwrap returns the error returned by the unmarshaller.
+func (err DecodeError) Unwrap() error { return err.Err }
+
+// decodeError returns DecodeError for 'err' returned by unmarshalling 'body'
+// into 'intoPtr'.
+func decodeError(body []byte, intoPtr interface{}, err error) DecodeError {
+	var derr DecodeError
+	if errors.As(err, &derr) {
+		return derr
+	}
+
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		return DecodeError{Offset: serr.Offset, Snippet: snippet(body, serr.Offset), Err: err}
+	}
+
+	if f := locate(body, reflect.TypeOf(intoPtr)); f != nil {
+		derr = f.decodeError()
+	}
+	derr.Err = err
+	return derr
+}
+
+// fault is a value in JSON data which fails to unmarshal.
+type fault struct {
+	path   []string // path elements, like ".key" or "[index]"
+	offset int
+	value  []byte
+	err    error
+}
+
+// pathString returns the path of the value, like "route[2].station".
+func (f fault) pathString() string {
+	return strings.TrimPrefix(strings.Join(f.path, ""), ".")
+}
+
+// decodeError returns DecodeError for the fault.
+func (f fault) decodeError() DecodeError {
+	return DecodeError{
+		Path:    f.pathString(),
+		Offset:  int64(f.offset),
+		Snippet: string(truncate(f.value)),
+		Err:     f.err,
+	}
+}
+
+// locate returns the innermost value in JSON 'data' which fails to
+// unmarshal into a value of type 't'. It returns nil if 'data' unmarshals
+// into 't'.
+func locate(data []byte, t reflect.Type) *fault {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	err := json.Unmarshal(data, reflect.New(t).Interface())
+	if err == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		ms := members(data)
+		for i, m := range ms {
+			var (
+				elem reflect.Type
+				v    = m.value
+				off  = m.offset
+				name = "[" + strconv.Itoa(i) + "]"
+			)
+
+			switch t.Kind() {
+			case reflect.Struct:
+				f, ok := fieldByKey(t, m.key)
+				if !ok {
+					continue
+				}
+				elem, name = f.Type, "."+m.key
+				if quoted(f) {
+					if s, err := strconv.Unquote(string(v)); err == nil {
+						v, off = []byte(s), off+1
+					}
+				}
+			case reflect.Map:
+				elem, name = t.Elem(), "."+m.key
+			default:
+				elem = t.Elem()
+			}
+
+			if f := locate(v, elem); f != nil {
+				f.path = append([]string{name}, f.path...)
+				f.offset += off
+				return f
+			}
+		}
+
+		// Value of a key unmarshalled by custom UnmarshalJSON method.
+		var ferr FieldError
+		if t.Kind() == reflect.Struct && errors.As(err, &ferr) {
+			for _, m := range ms {
+				if m.key == ferr.Key {
+					return &fault{[]string{"." + m.key}, m.offset, m.value, err}
+				}
+			}
+		}
+	}
+	return &fault{nil, 0, data, err}
+}
+
+// member is a member of a JSON object or array.
+type member struct {
+	key    string // empty for arrays
+	offset int
+	value  json.RawMessage
+}
+
+// members returns the members of JSON object or array 'data', in order.
+func members(data []byte) []member {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return nil
+	}
+
+	var ms []member
+	for dec.More() {
+		var m member
+		if delim == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return ms
+			}
+			m.key, _ = tok.(string)
+		}
+		if err := dec.Decode(&m.value); err != nil {
+			return ms
+		}
+		m.offset = int(dec.InputOffset()) - len(m.value)
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// fieldByKey returns the field of struct type 't', including the fields of
+// its embedded structs, which is unmarshalled from JSON object key 'key'.
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if ef, ok := fieldByKey(ft, key); ok {
+					return ef, true
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// quoted reports whether field 'f' is JSON encoded in a string.
+func quoted(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	for _, o := range strings.Split(opts, ",") {
+		if o == "string" {
+			return true
+		}
+	}
+	return false
+}
+
+// snippet returns 'body' around 'offset'.
+func snippet(body []byte, offset int64) string {
+	start, end := offset-snippetSize/2, offset+snippetSize/2
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(body)) {
+		end = int64(len(body))
+	}
+	return string(body[start:end])
+}
+
+// truncate truncates 'b' to snippetSize.
+func truncate(b []byte) []byte {
+	if len(b) > snippetSize {
+		return b[:snippetSize]
+	}
+	return b
+}
+
+// debug sets the raw body and HTTP metadata of the Response embedded in
+// the struct pointed by 'intoPtr'. 'rsp' is nil for cached responses.
+func debug(intoPtr interface{}, body []byte, rsp *http.Response, d time.Duration) {
+	r := embedded(intoPtr)
+	if r == nil {
+		return
+	}
+
+	r.Raw = append(json.RawMessage(nil), body...)
+	if rsp != nil {
+		r.HTTP = &HTTPMetadata{
+			StatusCode: rsp.StatusCode,
+			Header:     rsp.Header,
+			Duration:   d,
+		}
+		if rsp.Request != nil {
+			r.HTTP.URL = RedactURL(rsp.Request.URL, APIKeyParam(rsp.Request))
+		}
+	}
+}
+
+// embedded returns the Response embedded in the struct pointed by
+// 'intoPtr', allocating it if nil. It returns nil if there is none.
+func embedded(intoPtr interface{}) *Response {
+	v := reflect.ValueOf(intoPtr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+
+	rt := reflect.TypeOf(&Response{})
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !v.Type().Field(i).Anonymous || f.Type() != rt {
+			continue
+		}
+
+		if f.IsNil() {
+			f.Set(reflect.New(rt.Elem()))
+		}
+		return f.Interface().(*Response)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestClientDebug(t *testing.T) {
+	const body = `{"response_code": 200, "debit": 1, "train": {"name": "JODHPUR EXP", "number": "14311"}}`
+
+	c := rail.NewClient("SECRETKEY")
+	c.HTTPClient = &http.Client{Transport: mockBody(body)}
+	c.Debug = true
+
+	r, err := c.TrainByNumber(context.Background(), 14311)
+	if err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+	if string(r.Raw) != body {
+		t.Errorf("expected: `%s`, actual `%s`", body, r.Raw)
+	}
+	if r.HTTP == nil || r.HTTP.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected HTTP details: %+v", r.HTTP)
+	}
+	expected := "https://api.railwayapi.com/v2/name-number/train/14311/apikey/REDACTED"
+	if r.HTTP.URL != expected {
+		t.Errorf("expected: `%s`, actual `%s`", expected, r.HTTP.URL)
+	}
+
+	c.Debug = false
+	if r, _ = c.TrainByNumber(context.Background(), 14311); r.Raw != nil || r.HTTP != nil {
+		t.Error("expected no debug details without Debug mode")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedPath   string
+		expectedOffset int64
+	}{
+		{
+			input:          `{"response_code": 200, "route": [{"station": {"code": "BE"}}, {"station": {"lat": "x"}}]}`,
+			expectedPath:   "route[1].station.lat",
+			expectedOffset: 82,
+		},
+		{
+			input:          `{"response_code": 200, "route": [{"no": 1, "scharr": "2x:00"}]}`,
+			expectedPath:   "route[0].scharr",
+			expectedOffset: 53,
+		},
+		{
+			input:          `{"response_code": 200, "train": {"number": "14x11"}}`,
+			expectedPath:   "train.number",
+			expectedOffset: 44,
+		},
+		{
+			input:          `{"response_code": 200, "train": `,
+			expectedPath:   "",
+			expectedOffset: 32,
+		},
+	}
+
+	for i, tt := range tests {
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(tt.input)}
+		c.Debug = true
+
+		r, err := c.LiveTrainStatus(context.Background(), 14311, time.Now())
+		var derr rail.DecodeError
+		if !errors.As(err, &derr) {
+			t.Fatalf("%d. expected DecodeError, got: %v", i, err)
+		}
+		if derr.Path != tt.expectedPath || derr.Offset != tt.expectedOffset || derr.Snippet == "" {
+			t.Errorf("%d. unexpected DecodeError: %+v", i, derr)
+		}
+		if string(r.Raw) != tt.input {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.input, r.Raw)
+		}
+	}
+}
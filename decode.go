@@ -0,0 +1,343 @@
+package rail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxWarnings is the maximum number of malformed values ignored in
+// DecodeLenient mode.
+const maxWarnings = 32
+
+// DecodeMode defines how Client handles unexpected values in responses.
+type DecodeMode uint8
+
+const (
+	// DecodeDefault fails on malformed values and ignores unknown fields.
+	// Malformed times which aren't 5 characters long, like "-" or "Source",
+	// are ignored.
+	DecodeDefault DecodeMode = iota
+	// DecodeStrict fails on malformed values and unknown fields, returning
+	// DecodeError with the path of the offending value. Times, dates and
+	// durations must be in the layout used by MarshalJSON, or placeholders
+	// like "Source" or "RIGHT TIME"; alternate layouts are rejected.
+	DecodeStrict
+	// DecodeLenient replaces malformed values with zero values, recording
+	// them as Warnings in the Response.
+	DecodeLenient
+)
+
+// FieldError is returned when a value in the response can't be parsed.
+type FieldError struct {
+	// Key is the JSON key of the value.
+	Key string
+	// Value is the malformed value.
+	Value string
+	// Err is the parse error.
+	Err error
+}
+
+// Error implements the error interface.
+func (err FieldError) Error() string {
+	return fmt.Sprintf("parse %s %q failed: %s", err.Key, err.Value, err.Err)
+}
+
+// Cause returns the parse error.
+func (err FieldError) Cause() error { return err.Err }
+
+// Unwrap returns the parse error.
+func (err FieldError) Unwrap() error { return err.Err }
+
+// ErrUnknownField is returned in DecodeStrict mode for JSON keys which
+// don't map to any field.
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrLayout is returned in DecodeStrict mode for times, dates and durations
+// which aren't in their canonical layout.
+var ErrLayout = errors.New("non-canonical layout")
+
+// Warning is a malformed value replaced by zero value in DecodeLenient mode.
+type Warning struct {
+	// Path is the path of the value in the response, like "route[2].scharr".
+	Path string
+	// Value is the malformed value.
+	Value string
+	// Err is the error unmarshalling the value.
+	Err error
+}
+
+// String returns the warning as text.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Err)
+}
+
+// wireKeys holds the JSON keys unmarshalled by custom UnmarshalJSON methods,
+// which don't map to fields by name.
+var wireKeys = map[reflect.Type][]string{
+	reflect.TypeOf(Day{}):   {"runs"},
+	reflect.TypeOf(Class{}): {"available"},
+	reflect.TypeOf(Route{}): {
+		"actarr_date", "scharr_date", "scharr", "schdep", "actdep", "actarr",
+	},
+	reflect.TypeOf(ExtendedTrain{}): {
+		"src_departure_time", "dest_arrival_time", "travel_time",
+	},
+	reflect.TypeOf(TrainWithTimings{}): {
+		"scharr", "schdep", "actdep", "actarr", "delayarr", "delaydep",
+	},
+	reflect.TypeOf(LiveTrainStatusResp{}): {"start_date"},
+	reflect.TypeOf(PNRStatusResp{}):       {"doj"},
+	reflect.TypeOf(TrainSemi{}):           {"start_time"},
+	reflect.TypeOf(RescheduledTrain{}): {
+		"time_diff", "rescheduled_date", "rescheduled_time",
+	},
+}
+
+// wireLayouts holds the canonical layouts of times and dates by JSON key,
+// as formatted by MarshalJSON. Durations have layout "hh:mm".
+var wireLayouts = map[string]string{
+	"scharr":             "15:04",
+	"schdep":             "15:04",
+	"actarr":             "15:04",
+	"actdep":             "15:04",
+	"delayarr":           "15:04",
+	"delaydep":           "15:04",
+	"src_departure_time": "15:04",
+	"dest_arrival_time":  "15:04",
+	"rescheduled_time":   "15:04",
+	"travel_time":        "hh:mm",
+	"time_diff":          "hh:mm",
+	"actarr_date":        "2 Jan 2006",
+	"scharr_date":        "2 Jan 2006",
+	"start_date":         "2 Jan 2006",
+	"start_time":         "2 Jan 2006",
+	"date":               "2-1-2006",
+	"doj":                "02-01-2006",
+	"rescheduled_date":   "02-01-2006",
+}
+
+// placeholders are the values API sends in place of times, like for
+// arrival at the source station. They are unmarshalled as nil.
+var placeholders = map[string]bool{
+	"":            true,
+	"-":           true,
+	"--":          true,
+	"na":          true,
+	"source":      true,
+	"destination": true,
+	"dest":        true,
+	"right time":  true,
+}
+
+// dateLayouts are the layouts of dates sent by API, tried in order.
+var dateLayouts = []string{
+	"2-1-2006",
+	"2 Jan 2006",
+	"2-Jan-2006",
+	"2 January 2006",
+	"2006-01-02",
+}
+
+// parseClock parses time of day 's' of JSON key 'key', like "09:05"
+// or "9:05", in IST on the zero date. Malformed values which aren't
+// 5 characters long are ignored, as API sends them for missing times.
+func parseClock(key, s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if placeholders[strings.ToLower(s)] {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation("15:04", s, IST)
+	if err != nil {
+		if len(s) != 5 {
+			return nil, nil
+		}
+		return nil, FieldError{key, s, err}
+	}
+	return &t, nil
+}
+
+// parseDate parses date 's' of JSON key 'key', like "5-4-2018" or
+// "5 Apr 2018", in IST.
+func parseDate(key, s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if placeholders[strings.ToLower(s)] {
+		return nil, nil
+	}
+
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, IST); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, FieldError{key, s, err}
+}
+
+// parseHours parses duration 's' of JSON key 'key' in hours and minutes,
+// like "02:35". Malformed values which aren't 5 characters long are
+// ignored, as with parseClock.
+func parseHours(key, s string) (*time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if placeholders[strings.ToLower(s)] {
+		return nil, nil
+	}
+
+	d, err := parseHM(s)
+	if err != nil {
+		if len(s) != 5 {
+			return nil, nil
+		}
+		return nil, FieldError{key, s, err}
+	}
+	return &d, nil
+}
+
+// parseHM parses duration 's' in hours and minutes, like "02:35".
+func parseHM(s string) (time.Duration, error) {
+	hm := strings.SplitN(s, ":", 2)
+	if len(hm) != 2 {
+		return 0, errors.New("expected hh:mm")
+	}
+	return time.ParseDuration(hm[0] + "h" + hm[1] + "m")
+}
+
+// canonical returns an error if JSON value 'data' of key 'key' isn't a
+// placeholder or in the canonical layout of the key. Keys without layouts
+// are skipped.
+func canonical(key string, data []byte) error {
+	layout, ok := wireLayouts[key]
+	if !ok {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if placeholders[strings.ToLower(strings.TrimSpace(s))] {
+		return nil
+	}
+
+	var formatted string
+	if layout == "hh:mm" {
+		d, err := parseHM(s)
+		if err != nil {
+			return FieldError{key, s, err}
+		}
+		formatted = formatHours(&d)
+	} else {
+		t, err := time.ParseInLocation(layout, s, IST)
+		if err != nil {
+			return FieldError{key, s, err}
+		}
+		formatted = t.Format(layout)
+	}
+
+	if formatted != s {
+		return FieldError{key, s, errors.Wrapf(ErrLayout, "expected %s", layout)}
+	}
+	return nil
+}
+
+// unknown returns the first JSON key in 'data' which doesn't map to a field
+// of type 't', or whose time, date or duration isn't in canonical layout.
+func unknown(data []byte, t reflect.Type) *fault {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil
+	}
+
+	for i, m := range members(data) {
+		elem, name := t, fmt.Sprintf("[%d]", i)
+		switch t.Kind() {
+		case reflect.Struct:
+			name = "." + m.key
+			f, ok := fieldByKey(t, m.key)
+			if !ok {
+				if !wireKey(t, m.key) {
+					return &fault{[]string{name}, m.offset, m.value, ErrUnknownField}
+				}
+				if err := canonical(m.key, m.value); err != nil {
+					return &fault{[]string{name}, m.offset, m.value, err}
+				}
+				continue
+			}
+			elem = f.Type
+		case reflect.Map:
+			elem, name = t.Elem(), "."+m.key
+		default:
+			elem = t.Elem()
+		}
+
+		if f := unknown(m.value, elem); f != nil {
+			f.path = append([]string{name}, f.path...)
+			f.offset += m.offset
+			return f
+		}
+	}
+	return nil
+}
+
+// wireKey reports whether 'key' is unmarshalled by the custom UnmarshalJSON
+// method of type 't'.
+func wireKey(t reflect.Type, key string) bool {
+	for _, k := range wireKeys[t] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// decode unmarshalls 'body' into 'intoPtr' as defined by 'mode', returning
+// warnings in DecodeLenient mode.
+func decode(body []byte, intoPtr interface{}, mode DecodeMode) ([]Warning, error) {
+	err := json.Unmarshal(body, intoPtr)
+
+	switch mode {
+	case DecodeStrict:
+		if err != nil {
+			return nil, err
+		}
+		if f := unknown(body, reflect.TypeOf(intoPtr)); f != nil {
+			return nil, f.decodeError()
+		}
+
+	case DecodeLenient:
+		var warnings []Warning
+		for err != nil && len(warnings) < maxWarnings {
+			f := locate(body, reflect.TypeOf(intoPtr))
+			if f == nil || len(f.path) == 0 {
+				// Root value can't be replaced.
+				break
+			}
+			warnings = append(warnings, Warning{f.pathString(), string(f.value), f.err})
+
+			// Replace the malformed value with null and unmarshal again.
+			var b bytes.Buffer
+			b.Write(body[:f.offset])
+			b.WriteString("null")
+			b.Write(body[f.offset+len(f.value):])
+			body = b.Bytes()
+
+			v := reflect.ValueOf(intoPtr).Elem()
+			v.Set(reflect.Zero(v.Type()))
+			err = json.Unmarshal(body, intoPtr)
+		}
+		return warnings, err
+	}
+	return nil, err
+}
@@ -0,0 +1,193 @@
+package rail_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestRouteTimes(t *testing.T) {
+	clock := func(s string) *time.Time {
+		c, _ := time.ParseInLocation("15:04", s, rail.IST)
+		return &c
+	}
+
+	tests := []struct {
+		input    string
+		expected *time.Time
+		err      bool
+	}{
+		{`"09:05"`, clock("09:05"), false},
+		{`"9:05"`, clock("09:05"), false},
+		{`"00:00"`, clock("00:00"), false},
+		{`"Source"`, nil, false},
+		{`"DEST"`, nil, false},
+		{`"-"`, nil, false},
+		{`""`, nil, false},
+		{`"25:00"`, nil, true},
+		{`"9x:05"`, nil, true},
+		{`"9.05"`, nil, false},
+		{`"N/A"`, nil, false},
+	}
+
+	for i, tt := range tests {
+		var r rail.Route
+		err := json.Unmarshal([]byte(`{"scharr": `+tt.input+`}`), &r)
+
+		var ferr rail.FieldError
+		if tt.err != errors.As(err, &ferr) {
+			t.Errorf("%d. expected FieldError: %t, got: %v", i, tt.err, err)
+			continue
+		}
+		if tt.err && ferr.Key != "scharr" {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, "scharr", ferr.Key)
+		}
+
+		actual := r.ScheduledArrivalTime
+		if (actual == nil) != (tt.expected == nil) || (actual != nil && !actual.Equal(*tt.expected)) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected, actual)
+		}
+	}
+}
+
+func TestClientDecode(t *testing.T) {
+	const (
+		malformed = `{"response_code": 200, "route": [{"scharr": "9:05"}, {"scharr": "2x:00", "station": {"lat": "x"}}]}`
+		unknown   = `{"response_code": 200, "route": [{"scharr": "09:05", "platform": 2}]}`
+	)
+
+	tests := []struct {
+		inputMode rail.DecodeMode
+		inputBody string
+
+		expectedPath     string
+		expectedWarnings []string
+	}{
+		{inputMode: rail.DecodeDefault, inputBody: malformed, expectedPath: "-"},
+		{inputMode: rail.DecodeDefault, inputBody: unknown},
+		{inputMode: rail.DecodeStrict, inputBody: malformed, expectedPath: "route[1].station.lat"},
+		{inputMode: rail.DecodeStrict, inputBody: unknown, expectedPath: "route[0].platform"},
+		{
+			inputMode:        rail.DecodeLenient,
+			inputBody:        malformed,
+			expectedWarnings: []string{"route[1].station.lat", "route[1].scharr"},
+		},
+	}
+
+	for i, tt := range tests {
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(tt.inputBody)}
+		c.Decode = tt.inputMode
+
+		r, err := c.LiveTrainStatus(context.Background(), 14311, time.Now())
+
+		var derr rail.DecodeError
+		switch tt.expectedPath {
+		case "":
+			if err != nil {
+				t.Errorf("%d. LiveTrainStatus failed: %s", i, err)
+				continue
+			}
+		case "-":
+			if err == nil || errors.As(err, &derr) {
+				t.Errorf("%d. expected error without DecodeError, got: %v", i, err)
+			}
+			continue
+		default:
+			if !errors.As(err, &derr) || derr.Path != tt.expectedPath {
+				t.Errorf("%d. expected DecodeError at `%s`, got: %v", i, tt.expectedPath, err)
+			}
+			continue
+		}
+
+		if len(r.Warnings) != len(tt.expectedWarnings) {
+			t.Fatalf("%d. expected %d warnings, actual %v", i, len(tt.expectedWarnings), r.Warnings)
+		}
+		for j, w := range r.Warnings {
+			if w.Path != tt.expectedWarnings[j] || w.Err == nil {
+				t.Errorf("%d. expected warning at `%s`, actual `%s`", i, tt.expectedWarnings[j], w)
+			}
+		}
+		if len(r.Route) == 0 || r.Route[0].ScheduledArrivalTime == nil {
+			t.Errorf("%d. expected valid values to be kept, actual %+v", i, r.Route)
+		}
+	}
+}
+
+func TestClientDecodeLayouts(t *testing.T) {
+	tests := []struct {
+		inputMode rail.DecodeMode
+		inputJSON string
+
+		expectedPath string
+	}{
+		{rail.DecodeDefault, `"scharr": "Source", "scharr_date": "5-4-2018"`, ""},
+		{rail.DecodeDefault, `"scharr": "9:05", "schdep": "N/A"`, ""},
+		{rail.DecodeStrict, `"scharr": "09:05", "scharr_date": "5 Apr 2018", "schdep": ""`, ""},
+		{rail.DecodeStrict, `"scharr": "Source", "schdep": "SOURCE"`, ""},
+		{rail.DecodeStrict, `"scharr": "9:05"`, "route[0].scharr"},
+		{rail.DecodeStrict, `"schdep": "N/A"`, "route[0].schdep"},
+		{rail.DecodeStrict, `"scharr_date": "5-4-2018"`, "route[0].scharr_date"},
+		{rail.DecodeStrict, `"scharr_date": "05 Apr 2018"`, "route[0].scharr_date"},
+	}
+
+	for i, tt := range tests {
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 200, "start_date": "5 Apr 2018", "route": [{` + tt.inputJSON + `}]}`)}
+		c.Decode = tt.inputMode
+
+		_, err := c.LiveTrainStatus(context.Background(), 14311, time.Now())
+
+		if tt.expectedPath == "" {
+			if err != nil {
+				t.Errorf("%d. LiveTrainStatus failed: %s", i, err)
+			}
+			continue
+		}
+
+		var derr rail.DecodeError
+		if !errors.As(err, &derr) || derr.Path != tt.expectedPath {
+			t.Errorf("%d. expected DecodeError at `%s`, got: %v", i, tt.expectedPath, err)
+		}
+	}
+}
+
+func TestClientDecodeStrictTestdata(t *testing.T) {
+	files, err := filepath.Glob(testDataDir + "*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatal("glob testdata failed:", err)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		i := 0
+		for i < len(fixtures) && fixtures[i].testdata != name {
+			i++
+		}
+		if i == len(fixtures) {
+			t.Errorf("%s: no fixture", name)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal("read testdata failed:", err)
+		}
+
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(string(data))}
+		c.Decode = rail.DecodeStrict
+
+		if err := c.Do(c.Auth(fixtures[i].req), fixtures[i].newV()); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
@@ -46,5 +46,42 @@ If you are using concrete Client, then you need to assign client.Auth field to m
   }
 
 This will add API Key to each request made by client methods.
+
+NewAuth adds API Key to the URL path. If the API is fronted by a proxy, use
+NewHeaderAuth or NewQueryAuth to send it in a header or query parameter instead.
+API Keys are redacted from URLs in errors returned by Client.
+
+Errors
+
+API responds with HTTP status 200 even for failed requests and reports the
+actual status in the response_code key. Client methods return ErrResponseCode
+for any response_code other than 200, which can be checked using errors.Is.
+
+  _, err := client.PNRStatus(ctx, 2124289856)
+  if errors.Is(err, rail.ErrFlushedPNR) {
+    // ...
+  }
+
+Tracing
+
+Assign client.Tracer to trace API calls. Each call of a client method starts a
+span with attributes of the endpoint, its parameters and response, and a child
+span for every request sent. Package railotel adapts OpenTelemetry tracers,
+and package railprom collects Prometheus metrics of calls.
+
+  client.Tracer = rail.MultiTracer(railotel.New(otel.Tracer("rail")), collector)
+
+Encoding
+
+Responses marshal to JSON in the format of API, with dates, times of day and
+flags like "Y"/"N" written as API sends them, so they can be cached, proxied or
+replayed and decoded by Client to the same values.
+
+Responses decoded by Client replay the body sent by API: unchanged values keep
+their keys in order, placeholders like "SOURCE" or "RIGHT TIME" and numbers
+with leading zeros, like train "04042". Changed values are encoded from the
+fields. json.Marshal compacts the output, dropping whitespace between tokens.
+
+  data, err := json.Marshal(status) // {"start_date": "5 Apr 2018", ...}
 */
 package rail
@@ -0,0 +1,154 @@
+package rail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// formatClock formats time of day 't' as sent by API, like "09:05".
+// It returns the empty string if 't' is nil.
+func formatClock(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(IST).Format("15:04")
+}
+
+// formatDate formats date 't' in 'layout', one of dateLayouts.
+// It returns the empty string if 't' is nil or zero.
+func formatDate(t *time.Time, layout string) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.In(IST).Format(layout)
+}
+
+// formatHours formats duration 'd' in hours and minutes, like "02:35".
+// It returns the empty string if 'd' is nil.
+func formatHours(d *time.Duration) string {
+	if d == nil {
+		return ""
+	}
+
+	h, m, sign := *d/time.Hour, *d%time.Hour/time.Minute, ""
+	if *d < 0 {
+		h, m, sign = -h, -m, "-"
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, h, m)
+}
+
+// replay returns the JSON encoding of response 'v' by 'marshal', replaying
+// the body of Response 'r' as sent by API where 'v' wasn't changed.
+//
+// Members of objects and elements of arrays are replayed as sent if their
+// value is unchanged, keeping the order of keys, placeholders like "SOURCE",
+// numbers with leading zeros and keys not decoded. Changed values are
+// encoded by 'marshal', and keys not sent by API are appended.
+func replay[T any](v T, r *Response, marshal func(T) ([]byte, error)) ([]byte, error) {
+	enc, err := marshal(v)
+	if err != nil || r == nil || len(r.wire) == 0 {
+		return enc, err
+	}
+
+	var orig T
+	if _, err := decode(r.wire, &orig, DecodeLenient); err != nil {
+		return enc, nil
+	}
+	origEnc, err := marshal(orig)
+	if err != nil {
+		return enc, nil
+	}
+	return merge(r.wire, origEnc, enc), nil
+}
+
+// merge returns JSON value 'wire' as sent by API, with the members and
+// elements whose encoding changed from 'orig' to 'cur' replaced by their
+// value in 'cur'.
+func merge(wire, orig, cur []byte) []byte {
+	if bytes.Equal(orig, cur) {
+		return wire
+	}
+
+	kind := func(data []byte) byte {
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			return data[0]
+		}
+		return 0
+	}
+	k := kind(wire)
+	if k != kind(orig) || k != kind(cur) {
+		return cur
+	}
+
+	switch k {
+	case '{':
+		return mergeObject(members(wire), members(orig), members(cur))
+	case '[':
+		w, o, c := members(wire), members(orig), members(cur)
+		if len(w) != len(o) || len(w) != len(c) {
+			return cur
+		}
+
+		var b bytes.Buffer
+		b.WriteByte('[')
+		for i := range w {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(merge(w[i].value, o[i].value, c[i].value))
+		}
+		b.WriteByte(']')
+		return b.Bytes()
+	default:
+		return cur
+	}
+}
+
+// mergeObject returns the JSON object of members 'wire', with members
+// changed from 'orig' to 'cur' merged, members left out of 'cur' removed,
+// and members added in 'cur' appended.
+func mergeObject(wire, orig, cur []member) []byte {
+	byKey := func(ms []member) map[string]json.RawMessage {
+		m := make(map[string]json.RawMessage, len(ms))
+		for _, mb := range ms {
+			m[mb.key] = mb.value
+		}
+		return m
+	}
+	om, cm, sent := byKey(orig), byKey(cur), make(map[string]bool, len(wire))
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	write := func(key string, value []byte) {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	for _, m := range wire {
+		sent[m.key] = true
+		o, inOrig := om[m.key]
+		c, inCur := cm[m.key]
+		switch {
+		case !inOrig && !inCur:
+			// Placeholders and keys not decoded.
+			write(m.key, m.value)
+		case inCur:
+			write(m.key, merge(m.value, o, c))
+		}
+	}
+	for _, m := range cur {
+		if !sent[m.key] {
+			write(m.key, m.value)
+		}
+	}
+
+	b.WriteByte('}')
+	return b.Bytes()
+}
@@ -0,0 +1,192 @@
+package rail_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+)
+
+// fixtures are the requests and response types of files in testDataDir.
+var fixtures = func() []struct {
+	testdata string
+	req      rail.Requester
+	newV     func() interface{}
+} {
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, rail.IST)
+
+	return []struct {
+		testdata string
+		req      rail.Requester
+		newV     func() interface{}
+	}{
+		{"TrainBetweenStations", rail.TrainBetweenStationsReq{"BE", "ADI", d}, func() interface{} { return new(rail.TrainBetweenStationsResp) }},
+		{"TrainArrivals", rail.TrainArrivalsReq{"BE", rail.WindowHour2}, func() interface{} { return new(rail.TrainArrivalsResp) }},
+		{"StationNameToCode", rail.StationNameToCodeReq{"BAREILLY"}, func() interface{} { return new(rail.Stations) }},
+		{"StationCodeToName", rail.StationCodeToNameReq{"BE"}, func() interface{} { return new(rail.Stations) }},
+		{"SuggestStation", rail.SuggestStationReq{"BARE"}, func() interface{} { return new(rail.Stations) }},
+		{"LiveTrainStatus", rail.LiveTrainStatusReq{12138, d}, func() interface{} { return new(rail.LiveTrainStatusResp) }},
+		{"TrainRoute", rail.TrainRouteReq{14311}, func() interface{} { return new(rail.TrainRouteResp) }},
+		{"CheckSeat", rail.CheckSeatReq{14311, "BE", "ADI", d, "SL", "GN"}, func() interface{} { return new(rail.CheckSeatResp) }},
+		{"PNRStatus", rail.PNRStatusReq{2144287856}, func() interface{} { return new(rail.PNRStatusResp) }},
+		{"TrainFare", rail.TrainFareReq{14311, "BE", "ADI", 24, d, "SL", "GN"}, func() interface{} { return new(rail.TrainFareResp) }},
+		{"TrainByNumber", rail.TrainByNumberReq{14311}, func() interface{} { return new(rail.TrainResp) }},
+		{"TrainByName", rail.TrainByNameReq{"DURONTO"}, func() interface{} { return new(rail.TrainResp) }},
+		{"CancelledTrains", rail.CancelledTrainsReq{d}, func() interface{} { return new(rail.CancelledTrainsResp) }},
+		{"RescheduledTrains", rail.RescheduledTrainsReq{d}, func() interface{} { return new(rail.RescheduledTrainsResp) }},
+		{"SuggestTrainByCode", rail.SuggestTrainByCodeReq{143}, func() interface{} { return new(rail.Trains) }},
+		{"SuggestTrainByName", rail.SuggestTrainByNameReq{"DURONTO"}, func() interface{} { return new(rail.Trains) }},
+	}
+}()
+
+func TestMarshalJSON(t *testing.T) {
+	for i, tt := range fixtures {
+		data, err := ioutil.ReadFile(testDataDir + tt.testdata + ".json")
+		if err != nil {
+			t.Fatal("read testdata failed:", err)
+		}
+
+		// Strict mode fails on keys not sent by API and on values not in
+		// their canonical layout.
+		c := rail.NewClient("API_KEY")
+		c.Decode = rail.DecodeStrict
+
+		// Responses decoded by Client are replayed as sent by API.
+		c.HTTPClient = &http.Client{Transport: mockBody(string(data))}
+		v := tt.newV()
+		if err := c.Do(c.Auth(tt.req), v); err != nil {
+			t.Errorf("%d. %s failed: %s", i, tt.testdata, err)
+			continue
+		}
+
+		replayed, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%d. marshal %s failed: %s", i, tt.testdata, err)
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			t.Fatal("compact testdata failed:", err)
+		}
+		if !bytes.Equal(replayed, compact.Bytes()) {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, compact.Bytes(), replayed)
+		}
+
+		// Responses built by users are encoded from their values.
+		expected := tt.newV()
+		if err := json.Unmarshal(data, expected); err != nil {
+			t.Errorf("%d. unmarshal %s failed: %s", i, tt.testdata, err)
+			continue
+		}
+		encoded, err := json.Marshal(expected)
+		if err != nil {
+			t.Errorf("%d. marshal %s failed: %s", i, tt.testdata, err)
+			continue
+		}
+
+		c.HTTPClient = &http.Client{Transport: mockBody(string(encoded))}
+		if err := c.Do(c.Auth(tt.req), tt.newV()); err != nil {
+			t.Errorf("%d. %s of encoded response failed: %s", i, tt.testdata, err)
+			continue
+		}
+
+		actual := tt.newV()
+		if err := json.Unmarshal(encoded, actual); err != nil {
+			t.Errorf("%d. unmarshal encoded %s failed: %s", i, tt.testdata, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%d. expected: `%+v`, actual `%+v`", i, expected, actual)
+		}
+	}
+}
+
+func TestMarshalJSONReplay(t *testing.T) {
+	data, err := ioutil.ReadFile(testDataDir + "LiveTrainStatus.json")
+	if err != nil {
+		t.Fatal("read testdata failed:", err)
+	}
+
+	c := rail.NewClient("API_KEY")
+	c.HTTPClient = &http.Client{Transport: mockBody(string(data))}
+	resp, err := c.LiveTrainStatus(context.Background(), 12138, time.Date(2018, time.April, 4, 0, 0, 0, 0, rail.IST))
+	if err != nil {
+		t.Fatal("LiveTrainStatus failed:", err)
+	}
+
+	// Changed values are encoded, unchanged ones are replayed as sent.
+	resp.PositionRemark = rail.Ptr("Arrived at BE")
+	late := 20
+	resp.Route[1].LateByMinutes = &late
+	resp.Route[0].ActualArrivalTime = nil
+
+	encoded, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal("marshal failed:", err)
+	}
+
+	tests := []struct {
+		expected string
+		contains bool
+	}{
+		{`"position":"Arrived at BE"`, true},
+		{`"latemin":20`, true},
+		{`"scharr":"Source"`, true},
+		{`"actarr":"00:00"`, false},
+		{`"station":{"lat":30.55414705,"code":"FZR","name":"FIROZPUR CANT","lng":74.2361440304861}`, true},
+		{`"latemin":0,"scharr_date":"4 Apr 2018","actdep":"22:07"`, false},
+	}
+	for i, tt := range tests {
+		if bytes.Contains(encoded, []byte(tt.expected)) != tt.contains {
+			t.Errorf("%d. expected `%s` in output: %t, actual `%s`", i, tt.expected, tt.contains, encoded)
+		}
+	}
+
+	var actual rail.LiveTrainStatusResp
+	if err := json.Unmarshal(encoded, &actual); err != nil {
+		t.Fatal("unmarshal failed:", err)
+	}
+	if rail.ValueOr(actual.PositionRemark, "") != "Arrived at BE" || rail.ValueOr(actual.Route[1].LateByMinutes, 0) != 20 {
+		t.Errorf("changed values not encoded: `%s`", encoded)
+	}
+}
+
+func TestMarshalJSONFormat(t *testing.T) {
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, rail.IST)
+	// 09:05 in IST.
+	clock := time.Date(2018, time.April, 5, 3, 35, 0, 0, time.UTC)
+	diff := -90 * time.Minute
+
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{rail.Available{Status: "AVAILABLE 5", Date: d}, `{"status":"AVAILABLE 5","date":"5-4-2018"}`},
+		{rail.Route{ScheduledArrivalDate: &d, ScheduledArrivalTime: &clock}, `{"scharr_date":"5 Apr 2018","scharr":"09:05"}`},
+		{rail.TrainSemi{StartDate: &d}, `{"start_time":"5 Apr 2018"}`},
+		{rail.LiveTrainStatusResp{StartDate: &d}, `{"start_date":"5 Apr 2018"}`},
+		{rail.PNRStatusResp{DateOfJourney: &d}, `{"doj":"05-04-2018"}`},
+		{rail.RescheduledTrain{TimeDifference: &diff, RescheduledDate: &d, RescheduledTime: &clock},
+			`{"time_diff":"-01:30","rescheduled_date":"05-04-2018","rescheduled_time":"09:05"}`},
+		{rail.Day{Code: "MON", Runs: true}, `{"code":"MON","runs":"Y"}`},
+		{rail.Class{Code: "SL", Available: rail.Ptr(false)}, `{"code":"SL","available":"N"}`},
+		{rail.Class{Code: "SL"}, `{"code":"SL"}`},
+	}
+
+	for i, tt := range tests {
+		data, err := json.Marshal(tt.input)
+		if err != nil {
+			t.Errorf("%d. marshal failed: %s", i, err)
+			continue
+		}
+		if string(data) != tt.expected {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.expected, data)
+		}
+	}
+}
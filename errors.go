@@ -0,0 +1,156 @@
+package rail
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Response codes returned by the API in the response_code key.
+//
+// Refer to following URL for more details.
+// https://railwayapi.com/api/
+const (
+	// CodeSuccess is returned for a successful request.
+	CodeSuccess = 200
+	// CodeTrainNotRunning is returned when train doesn't run on the date queried.
+	CodeTrainNotRunning = 210
+	// CodeClassNotAvailable is returned when train doesn't have journey class queried.
+	CodeClassNotAvailable = 211
+	// CodeFlushedPNR is returned for a flushed PNR.
+	CodeFlushedPNR = 220
+	// CodeInvalidPNR is returned for an invalid PNR.
+	CodeInvalidPNR = 221
+	// CodeInvalidDate is returned when date chosen for the query is not valid
+	// for the chosen parameters.
+	CodeInvalidDate = 230
+	// CodeNoData is returned when data couldn't be fetched as no data is available.
+	CodeNoData = 404
+	// CodeServiceUnavailable is returned when data couldn't be loaded on API
+	// servers and request couldn't go through.
+	CodeServiceUnavailable = 405
+	// CodeInvalidAPIKey is returned for an unauthorized API Key.
+	CodeInvalidAPIKey = 500
+	// CodeAccountExpired is returned when the account has expired or its
+	// credits are exhausted.
+	CodeAccountExpired = 501
+	// CodeInvalidArguments is returned when invalid arguments are passed.
+	CodeInvalidArguments = 502
+)
+
+var responseCodeText = map[int]string{
+	CodeSuccess:            "Success",
+	CodeTrainNotRunning:    "Train doesn't run on the date queried",
+	CodeClassNotAvailable:  "Train doesn't have journey class queried",
+	CodeFlushedPNR:         "Flushed PNR",
+	CodeInvalidPNR:         "Invalid PNR",
+	CodeInvalidDate:        "Date chosen for the query is not valid for the chosen parameters",
+	CodeNoData:             "No data available",
+	CodeServiceUnavailable: "Request couldn't go through",
+	CodeInvalidAPIKey:      "Unauthorized API Key",
+	CodeAccountExpired:     "Account Expired",
+	CodeInvalidArguments:   "Invalid arguments passed",
+}
+
+// ResponseCodeText returns a text for the API response code.
+// It returns the empty string if the code is unknown.
+func ResponseCodeText(code int) string {
+	return responseCodeText[code]
+}
+
+var (
+	// ErrTrainNotRunning is returned when train doesn't run on the date queried.
+	ErrTrainNotRunning = ErrResponseCode{Code: CodeTrainNotRunning}
+	// ErrClassNotAvailable is returned when train doesn't have journey class queried.
+	ErrClassNotAvailable = ErrResponseCode{Code: CodeClassNotAvailable}
+	// ErrFlushedPNR is returned for a flushed PNR.
+	ErrFlushedPNR = ErrResponseCode{Code: CodeFlushedPNR}
+	// ErrInvalidPNR is returned for an invalid PNR.
+	ErrInvalidPNR = ErrResponseCode{Code: CodeInvalidPNR}
+	// ErrInvalidDate is returned when date is not valid for the query.
+	ErrInvalidDate = ErrResponseCode{Code: CodeInvalidDate}
+	// ErrNoData is returned when no data is available for the query,
+	// e.g. for an unknown train number or station.
+	ErrNoData = ErrResponseCode{Code: CodeNoData}
+	// ErrTrainNotFound is returned when no data is available for the train
+	// queried by LiveTrainStatus, TrainRoute, CheckSeat, TrainFare,
+	// TrainByNumber and TrainByName calls. Errors matching it also match
+	// ErrNoData, but not the other way around: 404 responses of other
+	// calls, like station lookups, don't match it.
+	ErrTrainNotFound = ErrResponseCode{Code: CodeNoData, train: true}
+	// ErrServiceUnavailable is returned when request couldn't go through
+	// the API servers. It is retryable.
+	ErrServiceUnavailable = ErrResponseCode{Code: CodeServiceUnavailable}
+	// ErrInvalidAPIKey is returned for an unauthorized API Key.
+	ErrInvalidAPIKey = ErrResponseCode{Code: CodeInvalidAPIKey}
+	// ErrCreditsExhausted is returned when the account has expired or
+	// has no credits left.
+	ErrCreditsExhausted = ErrResponseCode{Code: CodeAccountExpired}
+	// ErrInvalidArguments is returned when invalid arguments are passed.
+	ErrInvalidArguments = ErrResponseCode{Code: CodeInvalidArguments}
+)
+
+// ErrResponseCode is returned by API calls when the response_code in the
+// response body isn't 200.
+//
+// Use errors.Is with one of the ErrXxx values above to check for a specific
+// code, or errors.As to access the debit charged for the call.
+type ErrResponseCode struct {
+	// Code is the response_code returned by the API.
+	Code int
+	// Debit is the value by which the user’s credit was debited.
+	Debit int
+
+	train bool // returned for a query of a train
+}
+
+// Error implements the error interface.
+func (err ErrResponseCode) Error() string {
+	if text := ResponseCodeText(err.Code); text != "" {
+		return fmt.Sprintf("rail: response code %d (%s)", err.Code, text)
+	}
+	return fmt.Sprintf("rail: response code %d", err.Code)
+}
+
+// Is reports whether target is an ErrResponseCode with the same Code.
+// ErrTrainNotFound matches only errors of calls querying a train.
+func (err ErrResponseCode) Is(target error) bool {
+	t, ok := target.(ErrResponseCode)
+	return ok && t.Code == err.Code && (!t.train || err.train)
+}
+
+// Retryable reports whether the request may succeed if sent again.
+func (err ErrResponseCode) Retryable() bool {
+	return err.Code == CodeServiceUnavailable
+}
+
+// trainPaths holds the path segments following the API version of
+// endpoints querying a train, like "live" in "v2/live/train/12138/...".
+var trainPaths = map[string]bool{
+	"live":        true,
+	"route":       true,
+	"check-seat":  true,
+	"fare":        true,
+	"name-number": true,
+}
+
+// err returns ErrResponseCode if the response_code isn't 200, for the
+// response of request 'req'.
+func (r Response) err(req *http.Request) error {
+	if r.ResponseCode == 0 || r.ResponseCode == CodeSuccess {
+		return nil
+	}
+	return ErrResponseCode{
+		Code:  r.ResponseCode,
+		Debit: r.Debit,
+		train: req != nil && trainPaths[pathEndpoint(req.URL)],
+	}
+}
+
+// envelope decodes the standard Response fields from the JSON response body.
+//
+// It returns zero Response if the body isn't a JSON object.
+func envelope(body []byte) (r Response) {
+	json.Unmarshal(body, &r)
+	return r
+}
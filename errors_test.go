@@ -0,0 +1,117 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestErrResponseCode(t *testing.T) {
+	tests := []struct {
+		body string
+
+		expected  error
+		debit     int
+		retryable bool
+	}{
+		{
+			body: `{"response_code": 200, "debit": 1}`,
+		},
+		{
+			body:     `{"response_code": 221, "debit": 1}`,
+			expected: rail.ErrInvalidPNR,
+			debit:    1,
+		},
+		{
+			body:     `{"response_code": 220, "debit": 1}`,
+			expected: rail.ErrFlushedPNR,
+			debit:    1,
+		},
+		{
+			body:     `{"response_code": 500, "debit": 0}`,
+			expected: rail.ErrInvalidAPIKey,
+		},
+		{
+			body:     `{"response_code": 501, "debit": 0}`,
+			expected: rail.ErrCreditsExhausted,
+		},
+		{
+			body:     `{"response_code": 404, "debit": 1}`,
+			expected: rail.ErrNoData,
+			debit:    1,
+		},
+		{
+			body:      `{"response_code": 405, "debit": 0}`,
+			expected:  rail.ErrServiceUnavailable,
+			retryable: true,
+		},
+		{
+			body:     `{"response_code": 299, "debit": 2}`,
+			expected: rail.ErrResponseCode{Code: 299},
+			debit:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(tt.body)}
+
+		resp, err := c.PNRStatus(context.Background(), 2144287856)
+		if tt.expected == nil {
+			if err != nil {
+				t.Fatalf("body `%s`: unexpected error: %s", tt.body, err)
+			}
+			continue
+		}
+
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("body `%s`: expected: `%v`, actual `%v`", tt.body, tt.expected, err)
+		}
+
+		var rerr rail.ErrResponseCode
+		if !errors.As(err, &rerr) {
+			t.Fatalf("body `%s`: expected ErrResponseCode, actual `%T`", tt.body, err)
+		}
+		if rerr.Debit != tt.debit || rerr.Retryable() != tt.retryable {
+			t.Fatalf("body `%s`: expected debit %d retryable %t, actual %d %t",
+				tt.body, tt.debit, tt.retryable, rerr.Debit, rerr.Retryable())
+		}
+
+		if resp.Response == nil || resp.Debit != tt.debit {
+			t.Fatalf("body `%s`: response not decoded", tt.body)
+		}
+	}
+}
+
+func TestErrTrainNotFound(t *testing.T) {
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, rail.IST)
+
+	tests := []struct {
+		call     func(c rail.Client) error
+		expected bool
+	}{
+		{func(c rail.Client) error { _, err := c.LiveTrainStatus(context.Background(), 12138, d); return err }, true},
+		{func(c rail.Client) error { _, err := c.TrainRoute(context.Background(), 14311); return err }, true},
+		{func(c rail.Client) error { _, err := c.TrainByName(context.Background(), "DURONTO"); return err }, true},
+		{func(c rail.Client) error { _, err := c.StationNameToCode(context.Background(), "BAREILLY"); return err }, false},
+		{func(c rail.Client) error { _, err := c.CancelledTrains(context.Background(), d); return err }, false},
+		{func(c rail.Client) error { _, err := c.PNRStatus(context.Background(), 2144287856); return err }, false},
+	}
+
+	for i, tt := range tests {
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 404, "debit": 1}`)}
+
+		err := tt.call(c)
+		if !errors.Is(err, rail.ErrNoData) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, rail.ErrNoData, err)
+		}
+		if errors.Is(err, rail.ErrTrainNotFound) != tt.expected {
+			t.Errorf("%d. expected train not found: %t, actual `%v`", i, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package rail
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoBackend is returned by Fallback when none of its backends
+	// could be tried, as all their circuit breakers are open.
+	ErrNoBackend = errors.New("rail: no available backend in fallback")
+)
+
+// Fallback is a Provider which tries its backends in order, returning the
+// response of the first one which doesn't fail.
+//
+// Backends can be any Provider, like a Client for railwayapi.com, a Client
+// with BaseURL of a mirror, or a Client serving from a Cache. Each backend
+// has a CircuitBreaker, so a failing backend is skipped until it recovers.
+//
+// A backend fails if it returns an error other than ErrResponseCode for
+// the query, like network errors, ErrAPI, ErrServiceUnavailable,
+// ErrInvalidAPIKey or ErrCreditsExhausted. Errors answering the query,
+// like ErrInvalidPNR, are returned without trying other backends.
+//
+// Fallback is safe for use by multiple go routines.
+type Fallback struct {
+	mu       sync.Mutex
+	backends []*backend
+}
+
+// backend is a Provider of Fallback.
+type backend struct {
+	p       Provider
+	breaker *CircuitBreaker
+
+	lastErr     error
+	lastFailure time.Time
+	lastSuccess time.Time
+}
+
+// BackendHealth holds health details of a backend of Fallback.
+type BackendHealth struct {
+	// Provider is the backend.
+	Provider Provider
+	// State is the state of backend's circuit breaker.
+	State BreakerState
+	// LastError is the error of the last failed call to backend.
+	LastError error
+	// LastFailure is the time of the last failed call to backend.
+	LastFailure time.Time
+	// LastSuccess is the time of the last successful call to backend.
+	LastSuccess time.Time
+}
+
+// NewFallback returns a new Fallback trying 'providers' in order, each with
+// a zero value CircuitBreaker.
+func NewFallback(providers ...Provider) *Fallback {
+	f := &Fallback{}
+	for _, p := range providers {
+		f.Add(p, nil)
+	}
+	return f
+}
+
+// Add adds a backend to be tried after the existing ones, with circuit
+// breaker 'b'. If 'b' is nil, a zero value CircuitBreaker is used.
+func (f *Fallback) Add(p Provider, b *CircuitBreaker) {
+	if b == nil {
+		b = &CircuitBreaker{}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.backends = append(f.backends, &backend{p: p, breaker: b})
+}
+
+// Health returns health details of the backends, in order.
+func (f *Fallback) Health() []BackendHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	health := make([]BackendHealth, 0, len(f.backends))
+	for _, b := range f.backends {
+		health = append(health, BackendHealth{
+			Provider:    b.p,
+			State:       b.breaker.State(),
+			LastError:   b.lastErr,
+			LastFailure: b.lastFailure,
+			LastSuccess: b.lastSuccess,
+		})
+	}
+	return health
+}
+
+// do calls 'call' with backends in order, until one doesn't fail.
+func (f *Fallback) do(ctx context.Context, call func(Provider) error) error {
+	f.mu.Lock()
+	backends := append([]*backend(nil), f.backends...)
+	f.mu.Unlock()
+
+	err := ErrNoBackend
+	for _, b := range backends {
+		if b.breaker.allow() != nil {
+			continue
+		}
+
+		err = call(b.p)
+		failed := backendFailed(err)
+		if ctx != nil && ctx.Err() != nil {
+			// Caller gave up, backend isn't to blame.
+			b.breaker.release()
+			return err
+		}
+		b.breaker.done(failed)
+
+		f.mu.Lock()
+		if failed {
+			b.lastErr, b.lastFailure = err, time.Now()
+		} else {
+			b.lastSuccess = time.Now()
+		}
+		f.mu.Unlock()
+
+		if !failed {
+			return err
+		}
+	}
+	return err
+}
+
+// TrainBetweenStations implements the Provider interface.
+func (f *Fallback) TrainBetweenStations(ctx context.Context,
+	FromStationCode string,
+	ToStationCode string,
+	Date time.Time,
+) (r TrainBetweenStationsResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainBetweenStations(ctx, FromStationCode, ToStationCode, Date)
+		return err
+	})
+	return r, err
+}
+
+// TrainArrivals implements the Provider interface.
+func (f *Fallback) TrainArrivals(ctx context.Context,
+	StationCode string,
+	Hours WindowHour,
+) (r TrainArrivalsResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainArrivals(ctx, StationCode, Hours)
+		return err
+	})
+	return r, err
+}
+
+// StationNameToCode implements the Provider interface.
+func (f *Fallback) StationNameToCode(ctx context.Context, StationName string) (r Stations, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.StationNameToCode(ctx, StationName)
+		return err
+	})
+	return r, err
+}
+
+// StationCodeToName implements the Provider interface.
+func (f *Fallback) StationCodeToName(ctx context.Context, StationCode string) (r Stations, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.StationCodeToName(ctx, StationCode)
+		return err
+	})
+	return r, err
+}
+
+// SuggestStation implements the Provider interface.
+func (f *Fallback) SuggestStation(ctx context.Context, StationName string) (r Stations, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.SuggestStation(ctx, StationName)
+		return err
+	})
+	return r, err
+}
+
+// LiveTrainStatus implements the Provider interface.
+func (f *Fallback) LiveTrainStatus(ctx context.Context,
+	TrainNumber uint32,
+	Date time.Time,
+) (r LiveTrainStatusResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.LiveTrainStatus(ctx, TrainNumber, Date)
+		return err
+	})
+	return r, err
+}
+
+// TrainRoute implements the Provider interface.
+func (f *Fallback) TrainRoute(ctx context.Context, TrainNumber uint32) (r TrainRouteResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainRoute(ctx, TrainNumber)
+		return err
+	})
+	return r, err
+}
+
+// CheckSeat implements the Provider interface.
+func (f *Fallback) CheckSeat(ctx context.Context,
+	TrainNumber uint32,
+	FromStationCode string,
+	ToStationCode string,
+	Class string,
+	Quota string,
+	Date time.Time,
+) (r CheckSeatResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.CheckSeat(ctx, TrainNumber, FromStationCode, ToStationCode, Class, Quota, Date)
+		return err
+	})
+	return r, err
+}
+
+// PNRStatus implements the Provider interface.
+func (f *Fallback) PNRStatus(ctx context.Context, PNRNumber uint64) (r PNRStatusResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.PNRStatus(ctx, PNRNumber)
+		return err
+	})
+	return r, err
+}
+
+// TrainFare implements the Provider interface.
+func (f *Fallback) TrainFare(ctx context.Context,
+	TrainNumber uint32,
+	FromStationCode string,
+	ToStationCode string,
+	Age uint8,
+	Class string,
+	Quota string,
+	Date time.Time,
+) (r TrainFareResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainFare(ctx, TrainNumber, FromStationCode, ToStationCode, Age, Class, Quota, Date)
+		return err
+	})
+	return r, err
+}
+
+// TrainByNumber implements the Provider interface.
+func (f *Fallback) TrainByNumber(ctx context.Context, TrainNumber uint32) (r TrainResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainByNumber(ctx, TrainNumber)
+		return err
+	})
+	return r, err
+}
+
+// TrainByName implements the Provider interface.
+func (f *Fallback) TrainByName(ctx context.Context, TrainName string) (r TrainResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.TrainByName(ctx, TrainName)
+		return err
+	})
+	return r, err
+}
+
+// CancelledTrains implements the Provider interface.
+func (f *Fallback) CancelledTrains(ctx context.Context, Date time.Time) (r CancelledTrainsResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.CancelledTrains(ctx, Date)
+		return err
+	})
+	return r, err
+}
+
+// RescheduledTrains implements the Provider interface.
+func (f *Fallback) RescheduledTrains(ctx context.Context, Date time.Time) (r RescheduledTrainsResp, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.RescheduledTrains(ctx, Date)
+		return err
+	})
+	return r, err
+}
+
+// SuggestTrainByName implements the Provider interface.
+func (f *Fallback) SuggestTrainByName(ctx context.Context, TrainName string) (r Trains, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.SuggestTrainByName(ctx, TrainName)
+		return err
+	})
+	return r, err
+}
+
+// SuggestTrainByCode implements the Provider interface.
+func (f *Fallback) SuggestTrainByCode(ctx context.Context, TrainCode uint32) (r Trains, err error) {
+	err = f.do(ctx, func(p Provider) (err error) {
+		r, err = p.SuggestTrainByCode(ctx, TrainCode)
+		return err
+	})
+	return r, err
+}
+
+var _ Provider = &Fallback{}
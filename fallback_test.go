@@ -0,0 +1,91 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestFallback(t *testing.T) {
+	primaryT := &sequenceTransport{bodies: []string{`{"response_code": 405}`}}
+	primary := rail.NewClient(getAPIKey())
+	primary.HTTPClient = &http.Client{Transport: primaryT}
+
+	secondaryT := &sequenceTransport{bodies: []string{`{"response_code": 200, "pnr": "2144287856"}`}}
+	secondary := rail.NewClient(getAPIKey())
+	secondary.HTTPClient = &http.Client{Transport: secondaryT}
+
+	f := &rail.Fallback{}
+	f.Add(primary, &rail.CircuitBreaker{Failures: 2, Cooldown: time.Hour})
+	f.Add(secondary, nil)
+
+	for i := 0; i < 3; i++ {
+		resp, err := f.PNRStatus(context.Background(), 2144287856)
+		if err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+		if resp.PNR == nil || *resp.PNR != 2144287856 {
+			t.Fatal("invalid response")
+		}
+	}
+
+	// Primary is skipped once its breaker opens.
+	if primaryT.calls != 2 || secondaryT.calls != 3 {
+		t.Fatalf("expected 2 and 3 calls, actual %d and %d", primaryT.calls, secondaryT.calls)
+	}
+
+	health := f.Health()
+	if health[0].State != rail.BreakerOpen || !errors.Is(health[0].LastError, rail.ErrServiceUnavailable) {
+		t.Fatalf("expected primary to be open, actual %+v", health[0])
+	}
+	if health[1].State != rail.BreakerClosed || health[1].LastSuccess.IsZero() {
+		t.Fatalf("expected secondary to be closed, actual %+v", health[1])
+	}
+}
+
+func TestFallbackAnswer(t *testing.T) {
+	primaryT := &sequenceTransport{bodies: []string{`{"response_code": 221}`}}
+	primary := rail.NewClient(getAPIKey())
+	primary.HTTPClient = &http.Client{Transport: primaryT}
+
+	secondaryT := &sequenceTransport{bodies: []string{`{"response_code": 200}`}}
+	secondary := rail.NewClient(getAPIKey())
+	secondary.HTTPClient = &http.Client{Transport: secondaryT}
+
+	f := rail.NewFallback(primary, secondary)
+
+	_, err := f.PNRStatus(context.Background(), 2144287856)
+	if !errors.Is(err, rail.ErrInvalidPNR) {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrInvalidPNR, err)
+	}
+	if secondaryT.calls != 0 {
+		t.Fatalf("expected no calls to secondary, actual %d", secondaryT.calls)
+	}
+}
+
+func TestFallbackNoBackend(t *testing.T) {
+	primaryT := &sequenceTransport{
+		statuses: []int{http.StatusBadGateway},
+		bodies:   []string{""},
+	}
+	primary := rail.NewClient(getAPIKey())
+	primary.HTTPClient = &http.Client{Transport: primaryT}
+
+	f := &rail.Fallback{}
+	f.Add(primary, &rail.CircuitBreaker{Failures: 1, Cooldown: time.Hour})
+
+	_, err := f.TrainByNumber(context.Background(), 14311)
+	var aerr rail.ErrAPI
+	if !errors.As(err, &aerr) {
+		t.Fatalf("expected ErrAPI, actual `%v`", err)
+	}
+
+	_, err = f.TrainByNumber(context.Background(), 14311)
+	if errors.Cause(err) != rail.ErrNoBackend {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrNoBackend, err)
+	}
+}
@@ -0,0 +1,116 @@
+package rail_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-india/rail"
+)
+
+// addSeeds adds the values at 'path' of testdata file 'name' to the seed
+// corpus of 'f'. Arrays at the end of 'path' add each of their elements.
+func addSeeds(f *testing.F, name string, path ...string) {
+	data, err := ioutil.ReadFile(testDataDir + name + ".json")
+	if err != nil {
+		f.Fatal("read testdata failed:", err)
+	}
+
+	for _, key := range path {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			f.Fatalf("unmarshal %s failed: %s", name, err)
+		}
+		data = fields[key]
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		f.Add(data)
+		return
+	}
+	for _, e := range elems {
+		f.Add([]byte(e))
+	}
+}
+
+// fuzzRoundTrip checks that data decoded into values of 'newV' encodes back
+// to data decoding to the same value.
+func fuzzRoundTrip(f *testing.F, newV func() interface{}) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v := newV()
+		if err := json.Unmarshal(data, v); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal %#v failed: %s", v, err)
+		}
+
+		v2 := newV()
+		if err := json.Unmarshal(encoded, v2); err != nil {
+			t.Fatalf("unmarshal `%s` failed: %s", encoded, err)
+		}
+
+		reencoded, err := json.Marshal(v2)
+		if err != nil {
+			t.Fatalf("marshal %#v failed: %s", v2, err)
+		}
+		if !bytes.Equal(encoded, reencoded) {
+			t.Errorf("expected: `%s`, actual `%s`", encoded, reencoded)
+		}
+	})
+}
+
+func FuzzAvailable(f *testing.F) {
+	addSeeds(f, "CheckSeat", "availability")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.Available) })
+}
+
+func FuzzDay(f *testing.F) {
+	addSeeds(f, "TrainRoute", "train", "days")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.Day) })
+}
+
+func FuzzClass(f *testing.F) {
+	addSeeds(f, "TrainRoute", "train", "classes")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.Class) })
+}
+
+func FuzzRoute(f *testing.F) {
+	addSeeds(f, "LiveTrainStatus", "route")
+	addSeeds(f, "TrainRoute", "route")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.Route) })
+}
+
+func FuzzTrainSemi(f *testing.F) {
+	addSeeds(f, "CancelledTrains", "trains")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.TrainSemi) })
+}
+
+func FuzzRescheduledTrain(f *testing.F) {
+	addSeeds(f, "RescheduledTrains", "trains")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.RescheduledTrain) })
+}
+
+func FuzzExtendedTrain(f *testing.F) {
+	addSeeds(f, "TrainBetweenStations", "trains")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.ExtendedTrain) })
+}
+
+func FuzzTrainWithTimings(f *testing.F) {
+	addSeeds(f, "TrainArrivals", "trains")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.TrainWithTimings) })
+}
+
+func FuzzLiveTrainStatusResp(f *testing.F) {
+	addSeeds(f, "LiveTrainStatus")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.LiveTrainStatusResp) })
+}
+
+func FuzzPNRStatusResp(f *testing.F) {
+	addSeeds(f, "PNRStatus")
+	fuzzRoundTrip(f, func() interface{} { return new(rail.PNRStatusResp) })
+}
@@ -0,0 +1,228 @@
+package rail
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoKeys is returned when a KeyPool has no usable API Key.
+	ErrNoKeys = errors.New("rail: no usable API key in pool")
+)
+
+// KeyPool is a pool of API Keys used to authenticate requests.
+//
+// Each request is authenticated with one of the keys, as NewAuth does,
+// rotating them round robin or by remaining credits. When the API responds
+// with ErrInvalidAPIKey or ErrCreditsExhausted, the key is disabled and the
+// request is sent again with the next key. Keys with exhausted credits are
+// enabled again at midnight IST.
+//
+// Assign its Auth method to client.Auth field to make client methods use
+// the pool for requests.
+//
+// KeyPool is safe for use by multiple go routines.
+type KeyPool struct {
+	// ByCredit rotates keys by their remaining credits, instead of
+	// round robin.
+	ByCredit bool
+
+	mu   sync.Mutex
+	keys []*poolKey
+	next int
+}
+
+// poolKey is an API Key of KeyPool.
+type poolKey struct {
+	key      string
+	budget   *CreditBudget
+	invalid  bool
+	disabled time.Time // disabled until
+}
+
+// KeyUsage holds usage details of an API Key of KeyPool.
+type KeyUsage struct {
+	// Key is the API Key, with all but its last 4 characters masked.
+	Key string
+	// Used is the credits debited for the key in the current day.
+	Used int
+	// Remaining is the credits remaining for the key in the current day.
+	// It is math.MaxInt for keys without a credit limit.
+	Remaining int
+	// Disabled reports whether the key is disabled as invalid or exhausted.
+	Disabled bool
+}
+
+// NewKeyPool returns a new KeyPool with 'keys' having no credit limit.
+func NewKeyPool(keys ...string) *KeyPool {
+	p := &KeyPool{}
+	for _, k := range keys {
+		p.Add(k, 0)
+	}
+	return p
+}
+
+// Add adds an API Key to the pool, with 'credits' daily credits.
+// If 'credits' is zero, the key has no credit limit.
+func (p *KeyPool) Add(key string, credits int) {
+	if credits <= 0 {
+		credits = math.MaxInt
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, &poolKey{key: key, budget: NewCreditBudget(credits)})
+}
+
+// Auth is an authenticator function adding API Key from the pool to
+// requests of 'r'.
+func (p *KeyPool) Auth(r Requester) Requester {
+	return poolRequester{p, r}
+}
+
+// Usage returns usage details of the API Keys in the pool.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]KeyUsage, 0, len(p.keys))
+	for _, k := range p.keys {
+		u := KeyUsage{
+			Key:       mask(k.key),
+			Used:      k.budget.Used(),
+			Remaining: k.budget.Remaining(),
+			Disabled:  !k.usable(now),
+		}
+		if k.budget.Limit() == math.MaxInt {
+			u.Remaining = math.MaxInt
+		}
+		usage = append(usage, u)
+	}
+	return usage
+}
+
+// pick returns the API Key to be used for the next request. Must hold p.mu.
+func (p *KeyPool) pick() (*poolKey, error) {
+	now := time.Now()
+
+	best := -1
+	for i := range p.keys {
+		j := (p.next + i) % len(p.keys)
+		if !p.keys[j].usable(now) {
+			continue
+		}
+		if best < 0 {
+			best = j
+			if !p.ByCredit {
+				break
+			}
+		}
+		if p.keys[j].budget.Remaining() > p.keys[best].budget.Remaining() {
+			best = j
+		}
+	}
+
+	if best < 0 {
+		return nil, ErrNoKeys
+	}
+	p.next = best + 1
+	return p.keys[best], nil
+}
+
+// find returns the pool's API Key used by 'req'. Must hold p.mu.
+func (p *KeyPool) find(req *http.Request) *poolKey {
+	segs := strings.Split(req.URL.Path, "/")
+	for i := 0; i < len(segs)-1; i++ {
+		if segs[i] != "apikey" {
+			continue
+		}
+		for _, k := range p.keys {
+			if k.key == segs[i+1] {
+				return k
+			}
+		}
+	}
+	return nil
+}
+
+// usable reports whether the key can be used at time 'now'.
+func (k *poolKey) usable(now time.Time) bool {
+	return !k.invalid && !now.Before(k.disabled) && k.budget.Remaining() > 0
+}
+
+// poolRequester adds API Key from KeyPool to the requests of Requester.
+type poolRequester struct {
+	p *KeyPool
+	r Requester
+}
+
+func (pr poolRequester) Request() (*http.Request, error) {
+	req, err := pr.r.Request()
+	if err != nil {
+		return req, errors.Wrap(err, "generate HTTP request failed")
+	}
+
+	pr.p.mu.Lock()
+	k, err := pr.p.pick()
+	pr.p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	req.URL.Path = path.Join(req.URL.Path, fmt.Sprintf("/apikey/%s/", k.key))
+	return req, nil
+}
+
+func (pr poolRequester) Unwrap() Requester { return pr.r }
+
+// observe records the credits debited for the key used by 'req', and
+// disables it if the API rejected it.
+func (pr poolRequester) observe(req *http.Request, body []byte, err error) bool {
+	pr.p.mu.Lock()
+	defer pr.p.mu.Unlock()
+
+	k := pr.p.find(req)
+	if k == nil {
+		return false
+	}
+
+	if body != nil {
+		k.budget.add(envelope(body).Debit)
+	}
+
+	switch {
+	case errors.Is(err, ErrInvalidAPIKey):
+		k.invalid = true
+	case errors.Is(err, ErrCreditsExhausted):
+		now := time.Now().In(IST)
+		y, m, d := now.Date()
+		k.disabled = time.Date(y, m, d+1, 0, 0, 0, 0, IST)
+	default:
+		return false
+	}
+
+	// Send again if there is another key to fail over to.
+	now := time.Now()
+	for _, k := range pr.p.keys {
+		if k.usable(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask masks all but the last 4 characters of 'key'.
+func mask(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
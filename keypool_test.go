@@ -0,0 +1,98 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+// keyTransport responds with response code by API Key in the request path.
+func keyTransport(codes map[string]string, used *[]string) mockTransport {
+	return func(r *http.Request) (*http.Response, error) {
+		key := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		*used = append(*used, key)
+		return mockBody(`{"response_code": ` + codes[key] + `, "debit": 1}`)(r)
+	}
+}
+
+func TestKeyPool(t *testing.T) {
+	var used []string
+	codes := map[string]string{"KEY1": "500", "KEY2": "501", "KEY3": "200"}
+
+	pool := rail.NewKeyPool("KEY1", "KEY2", "KEY3")
+	c := rail.Client{Auth: pool.Auth}
+	c.HTTPClient = &http.Client{Transport: keyTransport(codes, &used)}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.PNRStatus(context.Background(), 2144287856); err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+	}
+
+	if output := strings.Join(used, ","); output != "KEY1,KEY2,KEY3,KEY3" {
+		t.Fatalf("expected: `KEY1,KEY2,KEY3,KEY3`, actual `%s`", output)
+	}
+
+	usage := pool.Usage()
+	if !usage[0].Disabled || !usage[1].Disabled || usage[2].Disabled {
+		t.Fatalf("expected KEY1 and KEY2 to be disabled, actual %+v", usage)
+	}
+	if usage[2].Used != 2 || usage[2].Key != "****" {
+		t.Fatalf("expected KEY3 to be used twice, actual %+v", usage[2])
+	}
+
+	codes["KEY3"] = "500"
+	_, err := c.PNRStatus(context.Background(), 2144287856)
+	if !errors.Is(err, rail.ErrInvalidAPIKey) {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrInvalidAPIKey, err)
+	}
+
+	_, err = c.PNRStatus(context.Background(), 2144287856)
+	if errors.Cause(err) != rail.ErrNoKeys {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrNoKeys, err)
+	}
+}
+
+func TestKeyPoolRotation(t *testing.T) {
+	var used []string
+	codes := map[string]string{"KEY1": "200", "KEY2": "200"}
+
+	pool := rail.NewKeyPool("KEY1", "KEY2")
+	c := rail.Client{Auth: pool.Auth}
+	c.HTTPClient = &http.Client{Transport: keyTransport(codes, &used)}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.PNRStatus(context.Background(), 2144287856); err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+	}
+
+	if output := strings.Join(used, ","); output != "KEY1,KEY2,KEY1" {
+		t.Fatalf("expected: `KEY1,KEY2,KEY1`, actual `%s`", output)
+	}
+
+	used = nil
+	pool = &rail.KeyPool{ByCredit: true}
+	pool.Add("KEY1", 2)
+	pool.Add("KEY2", 3)
+	c.Auth = pool.Auth
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.PNRStatus(context.Background(), 2144287856); err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+	}
+
+	if output := strings.Join(used, ","); output != "KEY2,KEY1,KEY2,KEY1,KEY2" {
+		t.Fatalf("expected: `KEY2,KEY1,KEY2,KEY1,KEY2`, actual `%s`", output)
+	}
+
+	_, err := c.PNRStatus(context.Background(), 2144287856)
+	if errors.Cause(err) != rail.ErrNoKeys {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrNoKeys, err)
+	}
+}
@@ -0,0 +1,200 @@
+package rail
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket rate limiter.
+//
+// Tokens are added to the bucket at a constant rate, up to the bucket size.
+// Each request takes a token from the bucket, waiting for one if it is empty.
+// RateLimiter is safe for use by multiple go routines.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // time to add one token
+	size     float64       // bucket size
+	tokens   float64       // tokens in bucket at 'last'
+	last     time.Time     // last time 'tokens' was updated
+}
+
+// NewRateLimiter returns a new RateLimiter allowing 'n' requests per
+// duration 'per', with bursts of up to 'n' requests.
+func NewRateLimiter(n int, per time.Duration) *RateLimiter {
+	if n <= 0 {
+		panic("rail: non-positive n for NewRateLimiter")
+	}
+	return &RateLimiter{
+		interval: per / time.Duration(n),
+		size:     float64(n),
+		tokens:   float64(n),
+	}
+}
+
+// Wait blocks until a request is allowed or 'ctx' is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+
+	// Take the token now, even if not yet in the bucket, so concurrent
+	// callers queue up behind each other.
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the token so other callers don't wait for it.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// refill adds tokens accumulated since the last update. Must hold l.mu.
+func (l *RateLimiter) refill(now time.Time) {
+	switch {
+	case l.interval <= 0:
+		l.tokens = l.size
+	case !l.last.IsZero():
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.size {
+			l.tokens = l.size
+		}
+	}
+	l.last = now
+}
+
+// ErrBudgetExceeded is returned by API calls when the client's CreditBudget
+// has no credits remaining for the day.
+type ErrBudgetExceeded struct {
+	// Limit is the daily credit limit of the budget.
+	Limit int
+	// Used is the credits debited in the day.
+	Used int
+	// Reset is the time when the budget resets.
+	Reset time.Time
+}
+
+// Error implements the error interface.
+func (err ErrBudgetExceeded) Error() string {
+	return fmt.Sprintf("rail: credit budget exceeded, used %d of %d until %s",
+		err.Used, err.Limit, err.Reset.Format(time.RFC3339))
+}
+
+// Is reports whether target is an ErrBudgetExceeded.
+func (err ErrBudgetExceeded) Is(target error) bool {
+	_, ok := target.(ErrBudgetExceeded)
+	return ok
+}
+
+// CreditBudget tracks credits debited by the API against a daily limit.
+//
+// The budget resets at midnight IST. A credit is reserved for every request
+// in flight, so concurrent requests can't overspend the budget.
+// CreditBudget is safe for use by multiple go routines.
+type CreditBudget struct {
+	mu       sync.Mutex
+	limit    int
+	used     int
+	reserved int // credits reserved for requests in flight
+	reset    time.Time
+}
+
+// NewCreditBudget returns a new CreditBudget allowing 'limit' credits per day.
+func NewCreditBudget(limit int) *CreditBudget {
+	return &CreditBudget{limit: limit}
+}
+
+// Limit returns the daily credit limit.
+func (b *CreditBudget) Limit() int {
+	return b.limit
+}
+
+// Used returns the credits debited in the current day.
+func (b *CreditBudget) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	return b.used
+}
+
+// Remaining returns the credits remaining in the current day, less the
+// credits reserved for requests in flight.
+func (b *CreditBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	if b.used+b.reserved >= b.limit {
+		return 0
+	}
+	return b.limit - b.used - b.reserved
+}
+
+// allow reserves a credit for a request, returning ErrBudgetExceeded if
+// there are no credits remaining. If it returns nil, the credits debited
+// for the request must be reported using settle.
+func (b *CreditBudget) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	if b.used+b.reserved < b.limit {
+		b.reserved++
+		return nil
+	}
+	return ErrBudgetExceeded{Limit: b.limit, Used: b.used, Reset: b.reset}
+}
+
+// settle releases the credit reserved by allow, recording 'debit' credits
+// actually debited by the API for the request.
+func (b *CreditBudget) settle(debit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	if b.reserved > 0 {
+		b.reserved--
+	}
+	b.used += debit
+}
+
+// add records 'debit' credits debited by the API.
+func (b *CreditBudget) add(debit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	b.used += debit
+}
+
+// roll resets the budget if the day is over. Must hold b.mu.
+func (b *CreditBudget) roll() {
+	now := time.Now().In(IST)
+	if now.Before(b.reset) {
+		return
+	}
+
+	y, m, d := now.Date()
+	b.reset = time.Date(y, m, d+1, 0, 0, 0, 0, IST)
+	b.used = 0
+}
@@ -0,0 +1,124 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := rail.NewRateLimiter(2, 100*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal("Wait failed:", err)
+		}
+	}
+
+	// 2 requests are allowed immediately, rest wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected requests to be limited, elapsed %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("expected: `%v`, actual `%v`", context.Canceled, err)
+	}
+}
+
+func TestCreditBudget(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{`{"response_code": 200, "debit": 2}`}}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Budget = rail.NewCreditBudget(3)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+			t.Fatal("TrainByNumber failed:", err)
+		}
+	}
+
+	if c.Budget.Used() != 4 || c.Budget.Remaining() != 0 {
+		t.Fatalf("expected used 4 remaining 0, actual %d %d", c.Budget.Used(), c.Budget.Remaining())
+	}
+
+	_, err := c.TrainByNumber(context.Background(), 14311)
+	if !errors.Is(err, rail.ErrBudgetExceeded{}) {
+		t.Fatalf("expected: ErrBudgetExceeded, actual `%v`", err)
+	}
+
+	if st.calls != 2 {
+		t.Fatalf("expected 2 calls, actual %d", st.calls)
+	}
+}
+
+func TestCreditBudgetConcurrent(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	mt := mockTransport(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return mockBody(`{"response_code": 200, "debit": 1}`)(r)
+	})
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: mt}
+	c.Budget = rail.NewCreditBudget(1)
+
+	// Calls in flight reserve the last credit, so only one is sent.
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := c.TrainByNumber(context.Background(), 14311)
+			errs <- err
+		}()
+	}
+
+	var exceeded int
+	for i := 0; i < 4; i++ {
+		if err := <-errs; errors.Is(err, rail.ErrBudgetExceeded{}) {
+			exceeded++
+		}
+	}
+	close(release)
+	if err := <-errs; err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+
+	if exceeded != 4 || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected 4 exceeded and 1 call, actual %d and %d", exceeded, calls)
+	}
+	if c.Budget.Used() != 1 || c.Budget.Remaining() != 0 {
+		t.Fatalf("expected used 1 remaining 0, actual %d %d", c.Budget.Used(), c.Budget.Remaining())
+	}
+}
+
+func TestCreditBudgetRefund(t *testing.T) {
+	st := &sequenceTransport{
+		statuses: []int{http.StatusBadGateway},
+		bodies:   []string{""},
+	}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Budget = rail.NewCreditBudget(1)
+
+	// Credit reserved for a request failing without a debit is refunded.
+	for i := 0; i < 2; i++ {
+		if _, err := c.TrainByNumber(context.Background(), 14311); errors.Is(err, rail.ErrBudgetExceeded{}) {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+	}
+	if c.Budget.Used() != 0 || c.Budget.Remaining() != 1 {
+		t.Fatalf("expected used 0 remaining 1, actual %d %d", c.Budget.Used(), c.Budget.Remaining())
+	}
+}
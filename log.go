@@ -0,0 +1,81 @@
+package rail
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultLogLevel returns the level at which a request which returned
+// 'err' is logged.
+//
+// Successful requests are logged at slog.LevelDebug, requests answered with
+// an ErrResponseCode, like ErrInvalidPNR, at slog.LevelInfo, and failed
+// requests, like network errors or ErrServiceUnavailable, at slog.LevelWarn.
+func DefaultLogLevel(err error) slog.Level {
+	switch {
+	case err == nil:
+		return slog.LevelDebug
+	case !backendFailed(err):
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// logLevel returns the level at which a request which returned 'err' is
+// logged by the client.
+func (c Client) logLevel(err error) slog.Level {
+	if c.LogLevel != nil {
+		return c.LogLevel(err)
+	}
+	return DefaultLogLevel(err)
+}
+
+// log logs the request 'req' of 'r' sent by the client, which returned
+// 'rsp' with 'body' and 'err' after 'd'.
+//
+// API Keys and PNR numbers are redacted from the URL and error.
+func (c Client) log(r Requester, req *http.Request, attempt int, d time.Duration,
+	rsp *http.Response, body []byte, err error,
+) {
+	if c.Logger == nil {
+		return
+	}
+
+	ctx := req.Context()
+	level := c.logLevel(err)
+	if !c.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	// PNR numbers are found in the path, as middleware may hide the type
+	// of PNRStatusReq.
+	redact := func(s string) string { return s }
+	if pnr := pathParam(req.URL.Path, "pnr"); pnr != "" {
+		redact = strings.NewReplacer(pnr, redacted).Replace
+	}
+	u := redactURL(req.URL, redacted, APIKeyParam(req))
+	u.Path = redactPath(u.Path, "pnr", redacted)
+
+	attrs := []slog.Attr{
+		slog.String("endpoint", endpoint(r)),
+		slog.String("method", req.Method),
+		slog.String("url", u.String()),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", d),
+	}
+	if rsp != nil {
+		attrs = append(attrs, slog.Int("status", rsp.StatusCode))
+	}
+	if body != nil {
+		e := envelope(body)
+		attrs = append(attrs, slog.Int("response_code", e.ResponseCode), slog.Int("debit", e.Debit))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", redact(err.Error())))
+	}
+
+	c.Logger.LogAttrs(ctx, level, "rail: request", attrs...)
+}
@@ -0,0 +1,89 @@
+package rail_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+)
+
+func TestClientLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	c := rail.NewClient("SECRETKEY")
+	c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 221, "debit": 1}`)}
+	c.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	c.PNRStatus(context.Background(), 2124289856)
+
+	out := buf.String()
+	for _, secret := range []string{"SECRETKEY", "2124289856"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains `%s`: %s", secret, out)
+		}
+	}
+
+	var record struct {
+		Level        string
+		Endpoint     string
+		URL          string
+		Attempt      int
+		Status       int
+		ResponseCode int `json:"response_code"`
+		Debit        int
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal("unmarshal log record failed:", err)
+	}
+	if record.Level != "INFO" || record.Endpoint != "PNRStatus" || record.Attempt != 1 ||
+		record.Status != http.StatusOK || record.ResponseCode != 221 || record.Debit != 1 {
+		t.Errorf("unexpected log record: %+v", record)
+	}
+	expected := "https://api.railwayapi.com/v2/pnr-status/pnr/REDACTED/apikey/REDACTED"
+	if record.URL != expected {
+		t.Errorf("expected: `%s`, actual `%s`", expected, record.URL)
+	}
+
+	// Successful requests are logged at debug level.
+	buf.Reset()
+	c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 200}`)}
+	c.TrainByNumber(context.Background(), 14311)
+	if buf.Len() != 0 {
+		t.Errorf("expected no log, got: %s", buf.String())
+	}
+
+	c.LogLevel = func(error) slog.Level { return slog.LevelError }
+	c.TrainByNumber(context.Background(), 14311)
+	if !strings.Contains(buf.String(), `"level":"ERROR"`) {
+		t.Errorf("expected log at ERROR level, got: %s", buf.String())
+	}
+}
+
+func TestClientLoggerMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+
+	c := rail.NewClient("SECRETKEY")
+	c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 221, "debit": 1}`)}
+	c.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	c.RequestMiddleware = []rail.RequestMiddleware{
+		func(r rail.Requester) rail.Requester { return opaqueRequester{r} },
+	}
+
+	// PNR numbers are redacted from the path if middleware hides PNRStatusReq.
+	c.PNRStatus(context.Background(), 2124289856)
+
+	out := buf.String()
+	if out == "" {
+		t.Fatal("expected log, got none")
+	}
+	for _, secret := range []string{"SECRETKEY", "2124289856"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains `%s`: %s", secret, out)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package rail
+
+import "net/http"
+
+// RequestMiddleware wraps a Requester, returning a Requester which
+// modifies or observes the requests generated by it.
+//
+// The returned Requester should implement an Unwrap method returning the
+// wrapped Requester, so its endpoint is known to ClassOf, DefaultCacheTTL
+// and tracers.
+//
+// Authenticators returned by NewAuth are RequestMiddleware.
+type RequestMiddleware func(Requester) Requester
+
+// ResponseMiddleware is called with the result of a request made by
+// Client.Do and returns the error to be returned by Do instead of 'err'.
+//
+// 'rsp' is the HTTP response received for the request. Its body can be read
+// again by the middleware. It is nil if no response was received, or if the
+// response was served from client's Cache.
+//
+// 'intoPtr' is the value passed to Do, holding the unmarshalled response.
+//
+// 'err' is the error returned so far, or nil if the request succeeded.
+type ResponseMiddleware func(rsp *http.Response, intoPtr interface{}, err error) error
+
+// RequestFunc returns a RequestMiddleware which calls 'f' with every
+// successfully generated request before it is sent.
+func RequestFunc(f func(*http.Request) error) RequestMiddleware {
+	return func(r Requester) Requester {
+		return funcRequester{f, r}
+	}
+}
+
+// funcRequester calls f with the requests of Requester.
+type funcRequester struct {
+	f func(*http.Request) error
+	r Requester
+}
+
+func (fr funcRequester) Request() (*http.Request, error) {
+	req, err := fr.r.Request()
+	if err != nil {
+		return req, err
+	}
+	return req, fr.f(req)
+}
+
+func (fr funcRequester) Unwrap() Requester { return fr.r }
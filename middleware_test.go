@@ -0,0 +1,96 @@
+package rail_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestMiddleware(t *testing.T) {
+	var order []string
+
+	transport := mockTransport(func(r *http.Request) (*http.Response, error) {
+		order = append(order, "send "+r.Header.Get("X-Trace"))
+		return mockBody(`{"response_code": 221, "debit": 1}`)(r)
+	})
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: transport}
+	c.RequestMiddleware = []rail.RequestMiddleware{
+		rail.RequestFunc(func(r *http.Request) error {
+			order = append(order, "request 1")
+			r.Header.Set("X-Trace", "1")
+			return nil
+		}),
+		rail.RequestFunc(func(r *http.Request) error {
+			order = append(order, "request 2")
+			r.Header.Set("X-Trace", r.Header.Get("X-Trace")+"2")
+			return nil
+		}),
+	}
+
+	errHandled := errors.New("handled")
+	c.ResponseMiddleware = []rail.ResponseMiddleware{
+		func(rsp *http.Response, intoPtr interface{}, err error) error {
+			order = append(order, "response 1")
+
+			body, _ := ioutil.ReadAll(rsp.Body)
+			if !strings.Contains(string(body), "221") {
+				t.Fatalf("expected response body, actual `%s`", body)
+			}
+
+			resp, ok := intoPtr.(*rail.PNRStatusResp)
+			if !ok || resp.ResponseCode != 221 {
+				t.Fatalf("expected unmarshalled response, actual `%v`", intoPtr)
+			}
+
+			if errors.Is(err, rail.ErrInvalidPNR) {
+				return errHandled
+			}
+			return err
+		},
+		func(rsp *http.Response, intoPtr interface{}, err error) error {
+			order = append(order, "response 2")
+			return err
+		},
+	}
+
+	_, err := c.PNRStatus(context.Background(), 2144287856)
+	if errors.Cause(err) != errHandled {
+		t.Fatalf("expected: `%v`, actual `%v`", errHandled, err)
+	}
+
+	expected := "request 1,request 2,send 12,response 1,response 2"
+	if output := strings.Join(order, ","); output != expected {
+		t.Fatalf("expected: `%s`, actual `%s`", expected, output)
+	}
+}
+
+func TestMiddlewareRequestError(t *testing.T) {
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 200}`)}
+	c.RequestMiddleware = []rail.RequestMiddleware{
+		rail.RequestFunc(func(r *http.Request) error { return errors.New("boom") }),
+	}
+
+	var rsp *http.Response
+	c.ResponseMiddleware = []rail.ResponseMiddleware{
+		func(r *http.Response, intoPtr interface{}, err error) error {
+			rsp = r
+			return err
+		},
+	}
+
+	_, err := c.PNRStatus(context.Background(), 2144287856)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected: `boom`, actual `%v`", err)
+	}
+	if rsp != nil {
+		t.Fatal("expected no response")
+	}
+}
@@ -0,0 +1,30 @@
+package rail
+
+// Response fields missing from API responses are nil pointers, so they
+// can be told apart from zero values. The following helpers access them
+// without nil checks.
+
+// Value returns the value pointed by 'p' and whether it is present. It
+// returns the zero value and false if 'p' is nil.
+//
+//	if day, ok := rail.Value(route.Day); ok {
+//		...
+//	}
+func Value[T any](p *T) (T, bool) {
+	if p == nil {
+		var zero T
+		return zero, false
+	}
+	return *p, true
+}
+
+// ValueOr returns the value pointed by 'p', or 'def' if 'p' is nil.
+func ValueOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// Ptr returns a pointer to 'v', for setting optional fields.
+func Ptr[T any](v T) *T { return &v }
@@ -0,0 +1,98 @@
+package rail_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-india/rail"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		input      *int
+		expected   int
+		expectedOk bool
+	}{
+		{nil, 0, false},
+		{rail.Ptr(0), 0, true},
+		{rail.Ptr(2), 2, true},
+	}
+
+	for i, tt := range tests {
+		actual, ok := rail.Value(tt.input)
+		if actual != tt.expected || ok != tt.expectedOk {
+			t.Errorf("%d. expected: `%d, %t`, actual `%d, %t`", i, tt.expected, tt.expectedOk, actual, ok)
+		}
+
+		expected := -1
+		if tt.expectedOk {
+			expected = tt.expected
+		}
+		if actual := rail.ValueOr(tt.input, -1); actual != expected {
+			t.Errorf("%d. expected: `%d`, actual `%d`", i, expected, actual)
+		}
+	}
+}
+
+func TestPresence(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   bool
+		expectedOk bool
+	}{
+		{`{"code":"SL"}`, false, false},
+		{`{"code":"SL","available":"N"}`, false, true},
+		{`{"code":"SL","available":"Y"}`, true, true},
+	}
+
+	for i, tt := range tests {
+		var c rail.Class
+		if err := json.Unmarshal([]byte(tt.input), &c); err != nil {
+			t.Fatalf("%d. UnmarshalJSON failed: %s", i, err)
+		}
+
+		actual, ok := rail.Value(c.Available)
+		if actual != tt.expected || ok != tt.expectedOk {
+			t.Errorf("%d. expected: `%t, %t`, actual `%t, %t`", i, tt.expected, tt.expectedOk, actual, ok)
+		}
+
+		// Marshaling back out keeps the availability present or absent.
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("%d. MarshalJSON failed: %s", i, err)
+		}
+		if string(data) != tt.input {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.input, data)
+		}
+	}
+}
+
+func TestPresenceRoute(t *testing.T) {
+	tests := []string{
+		`{"no":1}`,
+		`{"no":1,"latemin":0}`,
+		`{"has_arrived":false,"no":1,"scharr":"09:05"}`,
+		`{"no":1,"actarr_date":"5 Apr 2018","schdep":"00:00"}`,
+	}
+
+	for i, input := range tests {
+		var r rail.Route
+		if err := json.Unmarshal([]byte(input), &r); err != nil {
+			t.Fatalf("%d. UnmarshalJSON failed: %s", i, err)
+		}
+
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("%d. MarshalJSON failed: %s", i, err)
+		}
+
+		var actual rail.Route
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Fatalf("%d. UnmarshalJSON failed: %s", i, err)
+		}
+		if !reflect.DeepEqual(r, actual) {
+			t.Errorf("%d. expected: `%+v`, actual `%+v` from `%s`", i, r, actual, data)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package rail
+
+import (
+	"context"
+	"time"
+)
+
+// Provider is implemented by any value that provides the data of
+// RailwayAPI's API functions.
+//
+// Client is the Provider backed by railwayapi.com. Other implementations can
+// provide alternative data sources, fakes for tests or compose multiple
+// providers, while callers use the same typed responses.
+type Provider interface {
+	StationProvider
+	StatusProvider
+	TrainProvider
+}
+
+// StationProvider provides station related API functions.
+type StationProvider interface {
+	// TrainBetweenStations gets trains running between stations.
+	TrainBetweenStations(ctx context.Context,
+		FromStationCode string,
+		ToStationCode string,
+		Date time.Time,
+	) (TrainBetweenStationsResp, error)
+
+	// TrainArrivals get list of trains arriving at a station within
+	// a window period along with their live status.
+	TrainArrivals(ctx context.Context,
+		StationCode string,
+		Hours WindowHour,
+	) (TrainArrivalsResp, error)
+
+	// StationNameToCode gets station details of the given station and
+	// its nearby stations using partial station name.
+	StationNameToCode(ctx context.Context, StationName string) (Stations, error)
+
+	// StationCodeToName gets station details of the given station code.
+	StationCodeToName(ctx context.Context, StationCode string) (Stations, error)
+
+	// SuggestStation suggests full station names given a partial station name.
+	SuggestStation(ctx context.Context, StationName string) (Stations, error)
+}
+
+// StatusProvider provides live status, availability and fare API functions.
+type StatusProvider interface {
+	// LiveTrainStatus gets live running status of a Train.
+	LiveTrainStatus(ctx context.Context,
+		TrainNumber uint32,
+		Date time.Time,
+	) (LiveTrainStatusResp, error)
+
+	// TrainRoute gets details about all the stations in the train’s route.
+	TrainRoute(ctx context.Context, TrainNumber uint32) (TrainRouteResp, error)
+
+	// CheckSeat gets train seat availability.
+	CheckSeat(ctx context.Context,
+		TrainNumber uint32,
+		FromStationCode string,
+		ToStationCode string,
+		Class string,
+		Quota string,
+		Date time.Time,
+	) (CheckSeatResp, error)
+
+	// PNRStatus gets PNR status details.
+	PNRStatus(ctx context.Context, PNRNumber uint64) (PNRStatusResp, error)
+
+	// TrainFare gets fares of a train.
+	TrainFare(ctx context.Context,
+		TrainNumber uint32,
+		FromStationCode string,
+		ToStationCode string,
+		Age uint8,
+		Class string,
+		Quota string,
+		Date time.Time,
+	) (TrainFareResp, error)
+}
+
+// TrainProvider provides train related API functions.
+type TrainProvider interface {
+	// TrainByNumber gets train details by its number.
+	TrainByNumber(ctx context.Context, TrainNumber uint32) (TrainResp, error)
+
+	// TrainByName gets train details by its name.
+	TrainByName(ctx context.Context, TrainName string) (TrainResp, error)
+
+	// CancelledTrains gets list of all cancelled trains on a particular day.
+	CancelledTrains(ctx context.Context, Date time.Time) (CancelledTrainsResp, error)
+
+	// RescheduledTrains gets list of all rescheduled trains on a particular date.
+	RescheduledTrains(ctx context.Context, Date time.Time) (RescheduledTrainsResp, error)
+
+	// SuggestTrainByName suggests full train names or numbers given a
+	// partial train name.
+	SuggestTrainByName(ctx context.Context, TrainName string) (Trains, error)
+
+	// SuggestTrainByCode suggests full train names or numbers given a
+	// partial train code.
+	SuggestTrainByCode(ctx context.Context, TrainCode uint32) (Trains, error)
+}
+
+// Client is the Provider backed by railwayapi.com.
+var _ Provider = Client{}
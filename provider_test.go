@@ -0,0 +1,29 @@
+package rail_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-india/rail"
+)
+
+// fakeProvider overrides PNRStatus of the embedded Provider.
+type fakeProvider struct {
+	rail.Provider
+}
+
+func (fakeProvider) PNRStatus(ctx context.Context, PNRNumber uint64) (rail.PNRStatusResp, error) {
+	return rail.PNRStatusResp{PNR: &PNRNumber}, nil
+}
+
+func TestProvider(t *testing.T) {
+	var p rail.Provider = fakeProvider{rail.NewClient(getAPIKey())}
+
+	resp, err := p.PNRStatus(context.Background(), 2144287856)
+	if err != nil {
+		t.Fatal("PNRStatus failed:", err)
+	}
+	if resp.PNR == nil || *resp.PNR != 2144287856 {
+		t.Fatal("invalid response")
+	}
+}
@@ -20,6 +20,10 @@ const (
 	WindowHour4
 )
 
+// IST is the Indian Standard Time location used by the API. Times in
+// responses are in IST.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
 // use a single instance of Validate, it caches struct info
 var validate = validator.New()
 
@@ -40,6 +44,20 @@ type Response struct {
 	// ResponseCode key included in each response contains the status
 	// of the result returned.
 	ResponseCode int `json:"response_code"`
+
+	// Raw is the raw JSON body of the response.
+	// It is set only by clients in Debug mode.
+	Raw json.RawMessage `json:"-"`
+	// HTTP holds details of the HTTP response. It is set only by clients
+	// in Debug mode, for responses not served from Cache.
+	HTTP *HTTPMetadata `json:"-"`
+	// Warnings holds the malformed values ignored by clients in
+	// DecodeLenient mode.
+	Warnings []Warning `json:"-"`
+
+	// wire is the JSON body of the response as sent by API, replayed by
+	// MarshalJSON of the response.
+	wire json.RawMessage
 }
 
 // Available holds an available item
@@ -61,19 +79,31 @@ func (a *Available) UnmarshalJSON(data []byte) error {
 
 	*a = Available(t.Alias)
 
-	if t.Date != "" {
-		date, err := time.Parse("2-1-2006", t.Date)
-		if err != nil {
-			return errors.Wrap(err, "parse Date failed")
-		}
-		a.Date = date
+	date, err := parseDate("date", t.Date)
+	if err != nil {
+		return err
+	}
+	if date != nil {
+		a.Date = *date
 	}
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (a Available) MarshalJSON() ([]byte, error) {
+	type Alias Available
+	return json.Marshal(struct {
+		Alias
+		Date string `json:"date"`
+	}{
+		Alias: Alias(a),
+		Date:  formatDate(&a.Date, "2-1-2006"),
+	})
+}
+
 // Day holds day details
 type Day struct {
-	Runs bool   // `json:"runs,omitempty"`
+	Runs bool   `json:"-"` // runs
 	Code string `json:"code,omitempty"`
 }
 
@@ -93,6 +123,19 @@ func (d *Day) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (d Day) MarshalJSON() ([]byte, error) {
+	type Alias Day
+	t := struct {
+		Alias
+		Run string `json:"runs"`
+	}{Alias: Alias(d), Run: "N"}
+	if d.Runs {
+		t.Run = "Y"
+	}
+	return json.Marshal(t)
+}
+
 // Quota holds quota details
 type Quota struct {
 	Name string `json:"name"`
@@ -100,8 +143,12 @@ type Quota struct {
 }
 
 // Class holds class details
+//
+// Available is nil if API doesn't send the availability of the class, and
+// MarshalJSON leaves it out then. It used to be false in that case; use
+// rail.ValueOr(class.Available, false) for the former behaviour.
 type Class struct {
-	Available *bool  // `json:"available,omitempty"`
+	Available *bool  `json:"-"` // available
 	Name      string `json:"name,omitempty"`
 	Code      string `json:"code,omitempty"`
 }
@@ -119,11 +166,28 @@ func (c *Class) UnmarshalJSON(data []byte) error {
 
 	*c = Class(t.Alias)
 
-	b := t.Avail == "Y"
-	c.Available = &b
+	if t.Avail != "" {
+		c.Available = Ptr(t.Avail == "Y")
+	}
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (c Class) MarshalJSON() ([]byte, error) {
+	type Alias Class
+	t := struct {
+		Alias
+		Avail string `json:"available,omitempty"`
+	}{Alias: Alias(c)}
+	if avail, ok := Value(c.Available); ok {
+		t.Avail = "N"
+		if avail {
+			t.Avail = "Y"
+		}
+	}
+	return json.Marshal(t)
+}
+
 // Train holds train details
 type Train struct {
 	Name    string  `json:"name"`
@@ -148,14 +212,18 @@ type Station struct {
 }
 
 // Route holds route details
+//
+// Times are resolved to instants in LiveTrainStatusResp. In TrainRouteResp
+// they are times of day on the zero date until anchored with
+// TrainRouteResp.Anchor.
 type Route struct {
-	ActualArrivalDate    *time.Time //`json:"actarr_date,omitempty"`
-	ScheduledArrivalDate *time.Time //`json:"scharr_date,omitempty"`
+	ActualArrivalDate    *time.Time `json:"-"` // actarr_date
+	ScheduledArrivalDate *time.Time `json:"-"` // scharr_date
 
-	ScheduledArrivalTime   *time.Time //`json:"scharr,omitempty"`
-	ScheduledDepartureTime *time.Time //`json:"schdep,omitempty"`
-	ActualDepartureTime    *time.Time //`json:"actdep,omitempty"`
-	ActualArrivalTime      *time.Time //`json:"actarr,omitempty"`
+	ScheduledArrivalTime   *time.Time `json:"-"` // scharr
+	ScheduledDepartureTime *time.Time `json:"-"` // schdep
+	ActualDepartureTime    *time.Time `json:"-"` // actdep
+	ActualArrivalTime      *time.Time `json:"-"` // actarr
 
 	HasArrived  *bool `json:"has_arrived,omitempty"`
 	HasDeparted *bool `json:"has_departed,omitempty"`
@@ -182,59 +250,60 @@ func (r *Route) UnmarshalJSON(data []byte) error {
 		ActualDepartureTime    string `json:"actdep"`
 		ActualArrivalTime      string `json:"actarr"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 
 	*r = Route(t.Alias)
 
-	if len(t.ScheduledArrivalTime) == 5 {
-		sa, err := time.Parse("15:04", t.ScheduledArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ScheduledArrival failed")
-		}
-		r.ScheduledArrivalTime = &sa
+	if r.ScheduledArrivalTime, err = parseClock("scharr", t.ScheduledArrivalTime); err != nil {
+		return err
 	}
 
-	if len(t.ScheduledDepartureTime) == 5 {
-		sd, err := time.Parse("15:04", t.ScheduledDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ScheduledDeparture failed")
-		}
-		r.ScheduledDepartureTime = &sd
+	if r.ScheduledDepartureTime, err = parseClock("schdep", t.ScheduledDepartureTime); err != nil {
+		return err
 	}
 
-	if len(t.ActualDepartureTime) == 5 {
-		ad, err := time.Parse("15:04", t.ActualDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ActualDeparture failed")
-		}
-		r.ActualDepartureTime = &ad
+	if r.ActualDepartureTime, err = parseClock("actdep", t.ActualDepartureTime); err != nil {
+		return err
 	}
 
-	if len(t.ActualArrivalTime) == 5 {
-		aa, err := time.Parse("15:04", t.ActualArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ActualArrival failed")
-		}
-		r.ActualArrivalTime = &aa
+	if r.ActualArrivalTime, err = parseClock("actarr", t.ActualArrivalTime); err != nil {
+		return err
 	}
 
-	if t.ActualArrivalDate != "" {
-		aad, err := time.Parse("2 Jan 2006", t.ActualArrivalDate)
-		if err != nil {
-			return errors.Wrap(err, "parse ActualArrivalDate failed")
-		}
-		r.ActualArrivalDate = &aad
+	if r.ActualArrivalDate, err = parseDate("actarr_date", t.ActualArrivalDate); err != nil {
+		return err
 	}
 
-	if t.ScheduledArrivalDate != "" {
-		sad, err := time.Parse("2 Jan 2006", t.ScheduledArrivalDate)
-		if err != nil {
-			return errors.Wrap(err, "parse ScheduledArrivalDate failed")
-		}
-		r.ScheduledArrivalDate = &sad
+	if r.ScheduledArrivalDate, err = parseDate("scharr_date", t.ScheduledArrivalDate); err != nil {
+		return err
 	}
 
 	return nil
 }
+
+// MarshalJSON convert struct to JSON data in the format of API
+func (r Route) MarshalJSON() ([]byte, error) {
+	type Alias Route
+	return json.Marshal(struct {
+		Alias
+		ActualArrivalDate    string `json:"actarr_date,omitempty"`
+		ScheduledArrivalDate string `json:"scharr_date,omitempty"`
+
+		ScheduledArrivalTime   string `json:"scharr,omitempty"`
+		ScheduledDepartureTime string `json:"schdep,omitempty"`
+		ActualDepartureTime    string `json:"actdep,omitempty"`
+		ActualArrivalTime      string `json:"actarr,omitempty"`
+	}{
+		Alias:                Alias(r),
+		ActualArrivalDate:    formatDate(r.ActualArrivalDate, "2 Jan 2006"),
+		ScheduledArrivalDate: formatDate(r.ScheduledArrivalDate, "2 Jan 2006"),
+
+		ScheduledArrivalTime:   formatClock(r.ScheduledArrivalTime),
+		ScheduledDepartureTime: formatClock(r.ScheduledDepartureTime),
+		ActualDepartureTime:    formatClock(r.ActualDepartureTime),
+		ActualArrivalTime:      formatClock(r.ActualArrivalTime),
+	})
+}
@@ -0,0 +1,70 @@
+// Package railotel adapts OpenTelemetry tracers for tracing rail clients.
+//
+//	client.Tracer = railotel.New(otel.Tracer("github.com/go-india/rail"))
+//
+// It is a separate module, so users of rail don't depend on OpenTelemetry.
+package railotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-india/rail"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a rail.Tracer starting OpenTelemetry client spans.
+type Tracer struct {
+	Tracer trace.Tracer
+}
+
+// New returns a new Tracer starting spans with 't'.
+func New(t trace.Tracer) Tracer {
+	return Tracer{t}
+}
+
+// Start implements the rail.Tracer interface.
+func (t Tracer) Start(ctx context.Context, name string, attrs ...rail.Attr) (context.Context, rail.Span) {
+	ctx, s := t.Tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+	return ctx, span{s}
+}
+
+// span adapts an OpenTelemetry span to rail.Span.
+type span struct{ s trace.Span }
+
+func (s span) SetAttributes(attrs ...rail.Attr) {
+	s.s.SetAttributes(convert(attrs)...)
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
+
+// convert converts rail attributes to OpenTelemetry ones.
+func convert(attrs []rail.Attr) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
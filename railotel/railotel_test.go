@@ -0,0 +1,75 @@
+package railotel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+	"github.com/go-india/rail/railotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mockTransport func(*http.Request) (*http.Response, error)
+
+func (mt mockTransport) RoundTrip(r *http.Request) (*http.Response, error) { return mt(r) }
+
+func TestTracer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	c := rail.NewClient("API_KEY")
+	c.Tracer = railotel.New(tp.Tracer("rail"))
+	c.HTTPClient = &http.Client{Transport: mockTransport(func(r *http.Request) (*http.Response, error) {
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			t.Error("request context doesn't hold span")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"response_code": 404, "debit": 1}`)),
+			Request:    r,
+		}, nil
+	})}
+
+	if _, err := c.TrainByNumber(context.Background(), 14311); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected %d spans, actual %d", 2, len(spans))
+	}
+
+	attempt, call := spans[0], spans[1]
+	if call.Name() != "rail.TrainByNumber" || call.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected call span: %s %s", call.Name(), call.SpanKind())
+	}
+	if attempt.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Error("attempt span isn't child of call span")
+	}
+	if call.Status().Code != codes.Error {
+		t.Errorf("expected: `%s`, actual `%s`", codes.Error, call.Status().Code)
+	}
+
+	expected := map[attribute.Key]attribute.Value{
+		rail.AttrEndpoint:     attribute.StringValue("TrainByNumber"),
+		rail.AttrTrainNumber:  attribute.IntValue(14311),
+		rail.AttrResponseCode: attribute.IntValue(404),
+		rail.AttrDebit:        attribute.IntValue(1),
+	}
+	actual := make(map[attribute.Key]attribute.Value)
+	for _, kv := range call.Attributes() {
+		actual[kv.Key] = kv.Value
+	}
+	for k, v := range expected {
+		if actual[k] != v {
+			t.Errorf("%s: expected: `%s`, actual `%s`", k, v.Emit(), actual[k].Emit())
+		}
+	}
+}
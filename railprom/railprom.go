@@ -0,0 +1,165 @@
+// Package railprom collects Prometheus metrics of rail clients.
+//
+//	c := railprom.New()
+//	prometheus.MustRegister(c)
+//	client.Tracer = c
+//
+// Use rail.MultiTracer to collect metrics along with tracing. It is a separate
+// module, so users of rail don't depend on the Prometheus client.
+package railprom
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// none is the label value of missing HTTP status and response_code.
+const none = "none"
+
+// Collector is a rail.Tracer collecting metrics of API calls, and a
+// prometheus.Collector exposing them:
+//
+//	rail_calls_total{endpoint, response_code}
+//	rail_call_duration_seconds{endpoint}
+//	rail_requests_total{endpoint, status}
+//	rail_credits_debited_total{endpoint}
+//	rail_cache_hits_total{endpoint}
+//
+// Calls are the calls of client methods, and requests are the HTTP requests
+// they send, including retries. Labels are "none" if the call failed before
+// getting an HTTP status or response_code.
+//
+// Collector is safe for use by multiple go routines.
+type Collector struct {
+	calls     *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	requests  *prometheus.CounterVec
+	credits   *prometheus.CounterVec
+	cacheHits *prometheus.CounterVec
+}
+
+// New returns a new Collector.
+func New() *Collector {
+	return &Collector{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rail_calls_total",
+			Help: "Number of API calls, by endpoint and response_code.",
+		}, []string{"endpoint", "response_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rail_call_duration_seconds",
+			Help:    "Duration of API calls, including retries, by endpoint.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rail_requests_total",
+			Help: "Number of HTTP requests sent, by endpoint and HTTP status.",
+		}, []string{"endpoint", "status"}),
+		credits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rail_credits_debited_total",
+			Help: "Credits debited by the API, by endpoint.",
+		}, []string{"endpoint"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rail_cache_hits_total",
+			Help: "Number of API calls served from cache, by endpoint.",
+		}, []string{"endpoint"}),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.calls.Describe(ch)
+	c.duration.Describe(ch)
+	c.requests.Describe(ch)
+	c.credits.Describe(ch)
+	c.cacheHits.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.calls.Collect(ch)
+	c.duration.Collect(ch)
+	c.requests.Collect(ch)
+	c.credits.Collect(ch)
+	c.cacheHits.Collect(ch)
+}
+
+// ctxKey is the context key of the endpoint of a call.
+type ctxKey struct{}
+
+// Start implements the rail.Tracer interface.
+func (c *Collector) Start(ctx context.Context, name string, attrs ...rail.Attr) (context.Context, rail.Span) {
+	s := &span{c: c, start: time.Now(), call: name != rail.SpanAttempt}
+	s.SetAttributes(attrs...)
+
+	if s.call {
+		return context.WithValue(ctx, ctxKey{}, s.endpoint), s
+	}
+	s.endpoint, _ = ctx.Value(ctxKey{}).(string)
+	return ctx, s
+}
+
+// span records the attributes of a call or request.
+type span struct {
+	c     *Collector
+	start time.Time
+	call  bool
+
+	endpoint     string
+	status       string
+	responseCode string
+	debit        int
+	cacheHit     bool
+}
+
+func (s *span) SetAttributes(attrs ...rail.Attr) {
+	for _, a := range attrs {
+		switch a.Key {
+		case rail.AttrEndpoint:
+			s.endpoint, _ = a.Value.(string)
+		case rail.AttrHTTPStatus:
+			s.status = label(a.Value)
+		case rail.AttrResponseCode:
+			s.responseCode = label(a.Value)
+		case rail.AttrDebit:
+			s.debit, _ = a.Value.(int)
+		case rail.AttrCacheHit:
+			s.cacheHit, _ = a.Value.(bool)
+		}
+	}
+}
+
+func (s *span) End(error) {
+	if !s.call {
+		s.c.requests.WithLabelValues(s.endpoint, or(s.status)).Inc()
+		if s.debit > 0 {
+			s.c.credits.WithLabelValues(s.endpoint).Add(float64(s.debit))
+		}
+		return
+	}
+
+	s.c.calls.WithLabelValues(s.endpoint, or(s.responseCode)).Inc()
+	s.c.duration.WithLabelValues(s.endpoint).Observe(time.Since(s.start).Seconds())
+	if s.cacheHit {
+		s.c.cacheHits.WithLabelValues(s.endpoint).Inc()
+	}
+}
+
+// label returns the label value of an int attribute value.
+func label(v interface{}) string {
+	if i, ok := v.(int); ok {
+		return strconv.Itoa(i)
+	}
+	return ""
+}
+
+// or returns 's', or none if empty.
+func or(s string) string {
+	if s == "" {
+		return none
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package railprom_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+	"github.com/go-india/rail/railprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// sequenceTransport responds with the next body on every request,
+// repeating the last one when exhausted.
+type sequenceTransport struct {
+	bodies []string
+	calls  int
+}
+
+func (st *sequenceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	i := st.calls
+	if i >= len(st.bodies) {
+		i = len(st.bodies) - 1
+	}
+	st.calls++
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(st.bodies[i])),
+		Request:    r,
+	}, nil
+}
+
+func TestCollector(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{
+		`{"response_code": 405, "debit": 0}`,
+		`{"response_code": 200, "debit": 1}`,
+		`{"response_code": 221, "debit": 1}`,
+	}}
+
+	col := railprom.New()
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(col)
+
+	c := rail.NewClient("API_KEY")
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Retry = &rail.RetryPolicy{MinBackoff: 1}
+	c.Cache = rail.NewLRUCache(10)
+	c.Tracer = col
+
+	ctx := context.Background()
+	c.TrainByNumber(ctx, 14311)
+	c.TrainByNumber(ctx, 14311) // From cache.
+	c.PNRStatus(ctx, 1234567890)
+
+	expected := `
+# HELP rail_cache_hits_total Number of API calls served from cache, by endpoint.
+# TYPE rail_cache_hits_total counter
+rail_cache_hits_total{endpoint="TrainByNumber"} 1
+# HELP rail_calls_total Number of API calls, by endpoint and response_code.
+# TYPE rail_calls_total counter
+rail_calls_total{endpoint="PNRStatus",response_code="221"} 1
+rail_calls_total{endpoint="TrainByNumber",response_code="200"} 2
+# HELP rail_credits_debited_total Credits debited by the API, by endpoint.
+# TYPE rail_credits_debited_total counter
+rail_credits_debited_total{endpoint="PNRStatus"} 1
+rail_credits_debited_total{endpoint="TrainByNumber"} 1
+# HELP rail_requests_total Number of HTTP requests sent, by endpoint and HTTP status.
+# TYPE rail_requests_total counter
+rail_requests_total{endpoint="PNRStatus",status="200"} 1
+rail_requests_total{endpoint="TrainByNumber",status="200"} 2
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"rail_cache_hits_total", "rail_calls_total", "rail_credits_debited_total", "rail_requests_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testutil.CollectAndCount(col, "rail_call_duration_seconds"); n != 2 {
+		t.Errorf("expected %d histograms, actual %d", 2, n)
+	}
+}
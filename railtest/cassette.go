@@ -0,0 +1,236 @@
+package railtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+// Mode defines how a Recorder uses its cassette.
+type Mode uint8
+
+const (
+	// ModeReplay replays the interactions of the cassette, failing requests
+	// not recorded.
+	ModeReplay Mode = iota
+	// ModeRecord sends all requests, recording them in a new cassette.
+	ModeRecord
+	// ModeRecordMissing replays the interactions of the cassette, sending
+	// and recording requests not recorded.
+	ModeRecordMissing
+)
+
+// ErrNotRecorded is returned by Recorder in ModeReplay for requests not
+// recorded in the cassette.
+var ErrNotRecorded = errors.New("interaction not recorded")
+
+// Cassette holds recorded HTTP interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded HTTP request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded HTTP request, with API Keys scrubbed.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// URL is the normalized URL, like "/v2/route/train/14311/apikey/REDACTED".
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// RecordedResponse is a recorded HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper recording interactions with the API in a
+// cassette file, and replaying them.
+//
+//	rec, err := railtest.NewRecorder("testdata/pnr.json", railtest.ModeRecordMissing)
+//	...
+//	c.HTTPClient = &http.Client{Transport: rec}
+//
+// Interactions are matched by method and normalized URL, the path and
+// sorted query with API Keys scrubbed, in the order they were recorded. The
+// last one matching is replayed again once all were replayed.
+type Recorder struct {
+	// Path is the path of the cassette file.
+	Path string
+	// Mode defines how the cassette is used.
+	Mode Mode
+	// Transport sends requests which are not replayed.
+	// http.DefaultTransport is used if nil.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	replayed map[string]int
+}
+
+// NewRecorder returns a new Recorder using cassette file 'path' in 'mode'.
+// The cassette file must exist unless recording.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Path: path, Mode: mode}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mode == ModeRecordMissing {
+		return r, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read cassette failed")
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, errors.Wrap(err, "unmarshal cassette failed")
+	}
+	return r, nil
+}
+
+// Cassette returns the interactions of the recorder.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, u := req.Method, normalize(req)
+
+	if r.Mode != ModeRecord {
+		if i, ok := r.match(method, u); ok {
+			return i.Response.response(req), nil
+		}
+		if r.Mode == ModeReplay {
+			return nil, errors.Wrapf(ErrNotRecorded, "%s %s", method, u)
+		}
+	}
+
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	rsp, err := t.RoundTrip(req)
+	if err != nil {
+		return rsp, err
+	}
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "read body failed")
+	}
+
+	i := Interaction{
+		Request: RecordedRequest{Method: method, URL: u, Header: scrub(req.Header)},
+		Response: RecordedResponse{
+			StatusCode: rsp.StatusCode,
+			Header:     scrub(rsp.Header),
+			Body:       string(body),
+		},
+	}
+	if err := r.record(i); err != nil {
+		return nil, err
+	}
+
+	rsp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return rsp, nil
+}
+
+// match returns the next interaction recorded for 'method' and URL 'u'.
+func (r *Recorder) match(method, u string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := method + " " + u
+	var matches []Interaction
+	for _, i := range r.cassette.Interactions {
+		if i.Request.Method == method && i.Request.URL == u {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+
+	if r.replayed == nil {
+		r.replayed = make(map[string]int)
+	}
+	n := r.replayed[key]
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	r.replayed[key] = n + 1
+	return matches[n], true
+}
+
+// record adds interaction 'i' to the cassette and saves it.
+func (r *Recorder) record(i Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal cassette failed")
+	}
+	return errors.Wrap(ioutil.WriteFile(r.Path, data, 0644), "write cassette failed")
+}
+
+// response returns the recorded response as a response to 'req'.
+func (rr RecordedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rr.Header.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
+
+// normalize returns the path and sorted query of the URL of 'req', with
+// API Keys redacted. Host is left out so cassettes can be replayed against
+// any server.
+func normalize(req *http.Request) string {
+	n := &url.URL{Path: "/" + strings.Trim(req.URL.Path, "/"), RawQuery: req.URL.RawQuery}
+	return rail.RedactURL(n, rail.APIKeyParam(req))
+}
+
+// scrub returns a copy of 'h' without headers which may hold API Keys or
+// credentials, like rail.DefaultAPIKeyHeader and cookies.
+func scrub(h http.Header) http.Header {
+	s := make(http.Header)
+	for k, v := range h {
+		lk := strings.ToLower(k)
+		if strings.Contains(lk, "key") || strings.Contains(lk, "cookie") || strings.HasSuffix(lk, "authorization") {
+			continue
+		}
+		s[k] = append([]string(nil), v...)
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
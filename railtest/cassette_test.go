@@ -0,0 +1,177 @@
+package railtest_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-india/rail"
+	"github.com/go-india/rail/railtest"
+	"github.com/pkg/errors"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	srv := railtest.NewServer("SECRETKEY")
+	srv.Respond("PNRStatus",
+		railtest.Response{Code: rail.CodeServiceUnavailable},
+		railtest.Response{Body: `{"pnr": "2144287856", "total_passengers": 2}`},
+	)
+
+	// Record
+	rec, err := railtest.NewRecorder(path, railtest.ModeRecord)
+	if err != nil {
+		t.Fatal("NewRecorder failed:", err)
+	}
+	c := srv.Client()
+	c.HTTPClient = &http.Client{Transport: rec}
+
+	if _, err := c.PNRStatus(ctx, 2144287856); !errors.Is(err, rail.ErrServiceUnavailable) {
+		t.Fatalf("expected: `%v`, actual `%v`", rail.ErrServiceUnavailable, err)
+	}
+	if _, err := c.PNRStatus(ctx, 2144287856); err != nil {
+		t.Fatal("PNRStatus failed:", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("read cassette failed:", err)
+	}
+	if strings.Contains(string(data), "SECRETKEY") {
+		t.Errorf("cassette contains API Key: %s", data)
+	}
+	if n := len(rec.Cassette().Interactions); n != 2 {
+		t.Fatalf("expected 2 interactions, actual %d", n)
+	}
+	srv.Close()
+
+	// Replay, against another server.
+	rec, err = railtest.NewRecorder(path, railtest.ModeReplay)
+	if err != nil {
+		t.Fatal("NewRecorder failed:", err)
+	}
+	srv = railtest.NewServer("OTHERKEY")
+	defer srv.Close()
+	c = srv.Client()
+	c.HTTPClient = &http.Client{Transport: rec}
+
+	if _, err := c.PNRStatus(ctx, 2144287856); !errors.Is(err, rail.ErrServiceUnavailable) {
+		t.Errorf("expected: `%v`, actual `%v`", rail.ErrServiceUnavailable, err)
+	}
+	for i := 0; i < 2; i++ {
+		r, err := c.PNRStatus(ctx, 2144287856)
+		if err != nil || rail.ValueOr(r.TotalPassengers, 0) != 2 {
+			t.Errorf("%d. unexpected response: %+v, %v", i, r, err)
+		}
+	}
+	if _, err := c.TrainRoute(ctx, 14311); !errors.Is(err, railtest.ErrNotRecorded) {
+		t.Errorf("expected: `%v`, actual `%v`", railtest.ErrNotRecorded, err)
+	}
+	if n := len(srv.Calls()); n != 0 {
+		t.Errorf("expected no calls in replay mode, actual %d", n)
+	}
+
+	// Record missing
+	rec, err = railtest.NewRecorder(path, railtest.ModeRecordMissing)
+	if err != nil {
+		t.Fatal("NewRecorder failed:", err)
+	}
+	c.HTTPClient = &http.Client{Transport: rec}
+
+	if _, err := c.PNRStatus(ctx, 2144287856); !errors.Is(err, rail.ErrServiceUnavailable) {
+		t.Errorf("expected: `%v`, actual `%v`", rail.ErrServiceUnavailable, err)
+	}
+	if _, err := c.TrainRoute(ctx, 14311); err != nil {
+		t.Error("TrainRoute failed:", err)
+	}
+	if n := len(srv.Calls()); n != 1 {
+		t.Errorf("expected 1 call, actual %d", n)
+	}
+	if n := len(rec.Cassette().Interactions); n != 3 {
+		t.Errorf("expected 3 interactions, actual %d", n)
+	}
+
+	if _, err := railtest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), railtest.ModeReplay); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestRecorderHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := railtest.NewRecorder(path, railtest.ModeRecord)
+	if err != nil {
+		t.Fatal("NewRecorder failed:", err)
+	}
+	rec.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": {"application/json"},
+				"Set-Cookie":   {"session=SECRET"},
+			},
+			Body:    ioutil.NopCloser(strings.NewReader(`{"response_code": 200}`)),
+			Request: req,
+		}, nil
+	})
+
+	c := rail.NewClient("API_KEY")
+	c.HTTPClient = &http.Client{Transport: rec}
+	if _, err := c.TrainRoute(context.Background(), 14311); err != nil {
+		t.Fatal("TrainRoute failed:", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("read cassette failed:", err)
+	}
+	if strings.Contains(string(data), "SECRET") {
+		t.Errorf("cassette contains cookie: %s", data)
+	}
+	if h := rec.Cassette().Interactions[0].Response.Header; h.Get("Content-Type") != "application/json" {
+		t.Errorf("expected Content-Type to be recorded, actual `%v`", h)
+	}
+}
+
+// trackedBody is a response body recording whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRecorderWriteError(t *testing.T) {
+	rec, err := railtest.NewRecorder(filepath.Join(t.TempDir(), "missing", "cassette.json"), railtest.ModeRecord)
+	if err != nil {
+		t.Fatal("NewRecorder failed:", err)
+	}
+
+	body := &trackedBody{Reader: strings.NewReader(`{"response_code": 200}`)}
+	rec.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body, Request: req}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/v2/route/train/14311", nil)
+	rsp, err := rec.RoundTrip(req)
+	if rsp != nil || err == nil {
+		t.Fatalf("expected write error, actual `%v`, `%v`", rsp, err)
+	}
+	if !body.closed {
+		t.Error("expected body to be closed")
+	}
+}
@@ -0,0 +1,343 @@
+// Package railtest provides a fake RailwayAPI server for testing code using
+// rail clients.
+//
+//	srv := railtest.NewServer("KEY")
+//	defer srv.Close()
+//
+//	srv.Respond("PNRStatus", railtest.Response{Code: rail.CodeFlushedPNR})
+//
+//	c := srv.Client()
+//	_, err := c.PNRStatus(ctx, 1234567890) // ErrFlushedPNR
+//
+// The server implements every endpoint of the API, serving sample payloads
+// unless scripted otherwise, and records the calls it receives. Server.Play
+// plays a Scenario of trains running and PNRs changing over the time of a
+// virtual Clock.
+package railtest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-india/rail"
+)
+
+//go:embed payloads/*.json
+var payloads embed.FS
+
+// route is an endpoint of the API.
+type route struct {
+	endpoint string
+	// segments of the path, with parameters enclosed in braces.
+	segments []string
+}
+
+// routes holds the endpoints of the API, matched in order.
+var routes = []route{
+	{"TrainBetweenStations", split("v2/between/source/{source}/dest/{dest}/date/{date}")},
+	{"TrainArrivals", split("v2/arrivals/station/{station}/hours/{hours}")},
+	{"StationNameToCode", split("v2/name-to-code/station/{station}")},
+	{"StationCodeToName", split("v2/code-to-name/code/{code}")},
+	{"SuggestStation", split("v2/suggest-station/name/{name}")},
+	{"LiveTrainStatus", split("v2/live/train/{train}/date/{date}")},
+	{"TrainRoute", split("v2/route/train/{train}")},
+	{"CheckSeat", split("v2/check-seat/train/{train}/source/{source}/dest/{dest}/date/{date}/pref/{pref}/quota/{quota}")},
+	{"PNRStatus", split("v2/pnr-status/pnr/{pnr}")},
+	{"TrainFare", split("v2/fare/train/{train}/source/{source}/dest/{dest}/age/{age}/pref/{pref}/quota/{quota}/date/{date}")},
+	{"TrainByNumber", split("v2/name-number/train/{train}")},
+	{"TrainByName", split("v2/name-number/train/{name}")},
+	{"CancelledTrains", split("v2/cancelled/date/{date}")},
+	{"RescheduledTrains", split("v2/rescheduled/date/{date}")},
+	{"SuggestTrainByCode", split("v2/suggest-train/train/{train}")},
+	{"SuggestTrainByName", split("v2/suggest-train/train/{name}")},
+}
+
+// params holds the formats of path parameters. Requests with parameters not
+// matching them are answered with rail.CodeInvalidArguments.
+var params = map[string]*regexp.Regexp{
+	"train": regexp.MustCompile(`^\d{1,5}$`),
+	"pnr":   regexp.MustCompile(`^\d{10}$`),
+	"date":  regexp.MustCompile(`^\d{2}-\d{2}-\d{4}$`),
+	"hours": regexp.MustCompile(`^[24]$`),
+	"age":   regexp.MustCompile(`^\d{1,3}$`),
+}
+
+func split(p string) []string { return strings.Split(strings.Trim(p, "/"), "/") }
+
+// match returns the parameters of 'segs' if they match the route, and
+// whether their values are valid.
+func (r route) match(segs []string) (ps map[string]string, valid bool) {
+	if len(segs) != len(r.segments) {
+		return nil, false
+	}
+
+	ps, valid = make(map[string]string), true
+	for i, s := range r.segments {
+		if !strings.HasPrefix(s, "{") {
+			if s != segs[i] {
+				return nil, false
+			}
+			continue
+		}
+
+		name := strings.Trim(s, "{}")
+		if re, ok := params[name]; ok && !re.MatchString(segs[i]) {
+			valid = false
+		}
+		ps[name] = segs[i]
+	}
+	return ps, valid
+}
+
+// Call is a request received by the server.
+type Call struct {
+	// Endpoint is the name of the endpoint, like "LiveTrainStatus", as
+	// named by the methods of rail.Client. It is empty for unknown paths.
+	Endpoint string
+	// Params holds the parameters in the path, like "train" or "date".
+	Params map[string]string
+	// APIKey is the key in the path, or else in the rail.DefaultAPIKeyHeader
+	// header or the rail.DefaultAPIKeyParam query parameter.
+	APIKey string
+	// URL is the URL of the request.
+	URL *url.URL
+	// Header is the header of the request.
+	Header http.Header
+	// Time is the time the request was received.
+	Time time.Time
+}
+
+// Response is a scripted response of the server.
+type Response struct {
+	// Code is the response_code. If zero, it is taken from Body, else
+	// rail.CodeSuccess.
+	Code int
+	// Debit is the debit of the response.
+	Debit int
+	// Body is the response, marshaled to JSON. It is a sample payload of
+	// the endpoint if nil and Code is rail.CodeSuccess, else an empty object.
+	Body interface{}
+	// Status is the HTTP status code, http.StatusOK if zero.
+	Status int
+}
+
+// Handler returns the response to a call.
+type Handler func(Call) Response
+
+// Server is a fake RailwayAPI server.
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the key accepted by the server. Requests with other keys
+	// are answered with rail.CodeInvalidAPIKey. Any key is accepted if empty.
+	APIKey string
+
+	mu       sync.Mutex
+	calls    []Call
+	handlers map[string]Handler
+	clock    *Clock
+}
+
+// NewServer starts and returns a new Server accepting 'APIKey'.
+// The caller should call Close when finished, to shut it down.
+func NewServer(APIKey string) *Server {
+	s := &Server{APIKey: APIKey, handlers: make(map[string]Handler)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a new rail client sending requests to the server with
+// the API Key of the server. The client uses the Clock of the scenario
+// played by the server, if any, as its current time.
+func (s *Server) Client() rail.Client {
+	u, _ := url.Parse(s.URL)
+	c := rail.NewClient(s.APIKey)
+	c.BaseURL = u
+	c.HTTPClient = s.Server.Client()
+	c.Now = s.now
+	return c
+}
+
+// now returns the time of the Clock played by the server, or the current
+// time if none.
+func (s *Server) now() time.Time {
+	s.mu.Lock()
+	clock := s.clock
+	s.mu.Unlock()
+
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
+
+// Handle sets 'h' to respond to calls of 'endpoint', like "PNRStatus".
+// A nil 'h' restores sample payloads.
+func (s *Server) Handle(endpoint string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h == nil {
+		delete(s.handlers, endpoint)
+		return
+	}
+	s.handlers[endpoint] = h
+}
+
+// Respond scripts the responses to the calls of 'endpoint', in order.
+// The last response is repeated for later calls.
+func (s *Server) Respond(endpoint string, rsps ...Response) {
+	if len(rsps) == 0 {
+		s.Handle(endpoint, nil)
+		return
+	}
+
+	var (
+		mu sync.Mutex
+		i  int
+	)
+	s.Handle(endpoint, func(Call) Response {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rsp := rsps[i]
+		if i < len(rsps)-1 {
+			i++
+		}
+		return rsp
+	})
+}
+
+// Calls returns the calls received by the server, in order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Reset forgets the calls received and the scripted responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.handlers = make(map[string]Handler)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	call := Call{URL: req.URL, Header: req.Header.Clone(), Time: time.Now()}
+
+	segs := split(req.URL.Path)
+	if n := len(segs); n >= 2 && segs[n-2] == "apikey" {
+		call.APIKey, segs = segs[n-1], segs[:n-2]
+	}
+	if call.APIKey == "" {
+		call.APIKey = req.Header.Get(rail.DefaultAPIKeyHeader)
+	}
+	if call.APIKey == "" {
+		call.APIKey = req.URL.Query().Get(rail.DefaultAPIKeyParam)
+	}
+
+	// Paths matching a route with invalid parameters are answered with
+	// rail.CodeInvalidArguments, unless a later route matches.
+	var valid bool
+	for _, r := range routes {
+		ps, ok := r.match(segs)
+		if ps == nil {
+			continue
+		}
+		if call.Params == nil || ok {
+			call.Endpoint, call.Params, valid = r.endpoint, ps, ok
+		}
+		if ok {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	h := s.handlers[call.Endpoint]
+	s.mu.Unlock()
+
+	var rsp Response
+	switch {
+	case req.Method != http.MethodGet:
+		rsp = Response{Status: http.StatusMethodNotAllowed}
+	case call.APIKey == "" || (s.APIKey != "" && call.APIKey != s.APIKey):
+		rsp = Response{Code: rail.CodeInvalidAPIKey}
+	case call.Endpoint == "":
+		rsp = Response{Status: http.StatusNotFound}
+	case !valid:
+		rsp = Response{Code: rail.CodeInvalidArguments}
+	case h != nil:
+		rsp = h(call)
+	}
+
+	body, err := rsp.encode(call.Endpoint)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := rsp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// encode returns the body of the response to a call of 'endpoint'.
+func (rsp Response) encode(endpoint string) ([]byte, error) {
+	if rsp.Status != 0 && rsp.Status != http.StatusOK && rsp.Body == nil {
+		return []byte(http.StatusText(rsp.Status) + "\n"), nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch b := rsp.Body.(type) {
+	case nil:
+		data = []byte("{}")
+		if rsp.Code == 0 || rsp.Code == rail.CodeSuccess {
+			data, err = payloads.ReadFile("payloads/" + endpoint + ".json")
+		}
+	case []byte:
+		data = b
+	case json.RawMessage:
+		data = b
+	case string:
+		data = []byte(b)
+	default:
+		data, err = json.Marshal(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Malformed bodies are sent as is.
+		return data, nil
+	}
+
+	if rsp.Code != 0 || fields["response_code"] == nil {
+		code := rsp.Code
+		if code == 0 {
+			code = rail.CodeSuccess
+		}
+		fields["response_code"], _ = json.Marshal(code)
+	}
+	if rsp.Debit != 0 || fields["debit"] == nil {
+		fields["debit"], _ = json.Marshal(rsp.Debit)
+	}
+	return json.Marshal(fields)
+}
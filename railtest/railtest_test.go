@@ -0,0 +1,231 @@
+package railtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/go-india/rail/railtest"
+	"github.com/pkg/errors"
+)
+
+func TestServer(t *testing.T) {
+	srv := railtest.NewServer("KEY")
+	defer srv.Close()
+
+	c := srv.Client()
+	ctx := context.Background()
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, rail.IST)
+
+	tests := []struct {
+		endpoint string
+		params   map[string]string
+		call     func() error
+	}{
+		{"TrainBetweenStations", map[string]string{"source": "BE", "dest": "ADI", "date": "05-04-2018"}, func() error {
+			r, err := c.TrainBetweenStations(ctx, "BE", "ADI", d)
+			return check(err, len(r.Trains))
+		}},
+		{"TrainArrivals", map[string]string{"station": "BE", "hours": "2"}, func() error {
+			r, err := c.TrainArrivals(ctx, "BE", rail.WindowHour2)
+			return check(err, len(r.Trains))
+		}},
+		{"StationNameToCode", map[string]string{"station": "BAREILLY"}, func() error {
+			r, err := c.StationNameToCode(ctx, "BAREILLY")
+			return check(err, len(r.Stations))
+		}},
+		{"StationCodeToName", map[string]string{"code": "BE"}, func() error {
+			r, err := c.StationCodeToName(ctx, "BE")
+			return check(err, len(r.Stations))
+		}},
+		{"SuggestStation", map[string]string{"name": "BARE"}, func() error {
+			r, err := c.SuggestStation(ctx, "BARE")
+			return check(err, len(r.Stations))
+		}},
+		{"LiveTrainStatus", map[string]string{"train": "12138", "date": "05-04-2018"}, func() error {
+			r, err := c.LiveTrainStatus(ctx, 12138, d)
+			return check(err, len(r.Route))
+		}},
+		{"TrainRoute", map[string]string{"train": "14311"}, func() error {
+			r, err := c.TrainRoute(ctx, 14311)
+			return check(err, len(r.Route))
+		}},
+		{"CheckSeat", map[string]string{"train": "14311", "source": "BE", "dest": "ADI", "date": "05-04-2018", "pref": "SL", "quota": "GN"}, func() error {
+			r, err := c.CheckSeat(ctx, 14311, "BE", "ADI", "SL", "GN", d)
+			return check(err, len(r.Availability))
+		}},
+		{"PNRStatus", map[string]string{"pnr": "2144287856"}, func() error {
+			r, err := c.PNRStatus(ctx, 2144287856)
+			return check(err, len(r.Passengers))
+		}},
+		{"TrainFare", map[string]string{"train": "14311", "source": "BE", "dest": "ADI", "age": "24", "pref": "SL", "quota": "GN", "date": "05-04-2018"}, func() error {
+			r, err := c.TrainFare(ctx, 14311, "BE", "ADI", 24, "SL", "GN", d)
+			return check(err, int(rail.ValueOr(r.Fare, 0)))
+		}},
+		{"TrainByNumber", map[string]string{"train": "14311"}, func() error {
+			r, err := c.TrainByNumber(ctx, 14311)
+			return check(err, len(r.Train.Name))
+		}},
+		{"TrainByName", map[string]string{"name": "DURONTO"}, func() error {
+			r, err := c.TrainByName(ctx, "DURONTO")
+			return check(err, len(r.Train.Name))
+		}},
+		{"CancelledTrains", map[string]string{"date": "05-04-2018"}, func() error {
+			r, err := c.CancelledTrains(ctx, d)
+			return check(err, len(r.Trains))
+		}},
+		{"RescheduledTrains", map[string]string{"date": "05-04-2018"}, func() error {
+			r, err := c.RescheduledTrains(ctx, d)
+			return check(err, len(r.Trains))
+		}},
+		{"SuggestTrainByCode", map[string]string{"train": "143"}, func() error {
+			r, err := c.SuggestTrainByCode(ctx, 143)
+			return check(err, len(r.Trains))
+		}},
+		{"SuggestTrainByName", map[string]string{"name": "DURONTO"}, func() error {
+			r, err := c.SuggestTrainByName(ctx, "DURONTO")
+			return check(err, len(r.Trains))
+		}},
+	}
+
+	for i, tt := range tests {
+		if err := tt.call(); err != nil {
+			t.Errorf("%d. %s failed: %s", i, tt.endpoint, err)
+			continue
+		}
+
+		calls := srv.Calls()
+		call := calls[len(calls)-1]
+		if call.Endpoint != tt.endpoint || call.APIKey != "KEY" {
+			t.Errorf("%d. expected call of `%s`, actual `%s` with key `%s`", i, tt.endpoint, call.Endpoint, call.APIKey)
+		}
+		for k, v := range tt.params {
+			if call.Params[k] != v {
+				t.Errorf("%d. expected %s: `%s`, actual `%s`", i, k, v, call.Params[k])
+			}
+		}
+	}
+
+	if n := len(srv.Calls()); n != len(tests) {
+		t.Errorf("expected %d calls, actual %d", len(tests), n)
+	}
+}
+
+// check returns an error if the response of a call is empty.
+func check(err error, n int) error {
+	if err == nil && n == 0 {
+		return errors.New("empty response")
+	}
+	return err
+}
+
+func TestServerRespond(t *testing.T) {
+	srv := railtest.NewServer("KEY")
+	defer srv.Close()
+
+	c := srv.Client()
+	ctx := context.Background()
+
+	srv.Respond("PNRStatus",
+		railtest.Response{Code: rail.CodeFlushedPNR},
+		railtest.Response{Body: map[string]interface{}{"pnr": "1234567890", "total_passengers": 4}, Debit: 1},
+	)
+
+	if _, err := c.PNRStatus(ctx, 1234567890); !errors.Is(err, rail.ErrFlushedPNR) {
+		t.Errorf("expected: `%v`, actual `%v`", rail.ErrFlushedPNR, err)
+	}
+	for i := 0; i < 2; i++ {
+		r, err := c.PNRStatus(ctx, 1234567890)
+		if err != nil {
+			t.Fatal("PNRStatus failed:", err)
+		}
+		if rail.ValueOr(r.TotalPassengers, 0) != 4 || r.Debit != 1 {
+			t.Errorf("%d. unexpected response: %+v", i, r)
+		}
+	}
+
+	srv.Handle("TrainRoute", func(call railtest.Call) railtest.Response {
+		return railtest.Response{Body: `{"train": {"number": "` + call.Params["train"] + `"}}`}
+	})
+	r, err := c.TrainRoute(ctx, 14311)
+	if err != nil || r.Train == nil || r.Train.Number != 14311 {
+		t.Errorf("unexpected response: %+v, %v", r, err)
+	}
+
+	srv.Reset()
+	if _, err := c.PNRStatus(ctx, 1234567890); err != nil {
+		t.Error("PNRStatus failed:", err)
+	}
+	if n := len(srv.Calls()); n != 1 {
+		t.Errorf("expected 1 call, actual %d", n)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := railtest.NewServer("KEY")
+	defer srv.Close()
+
+	tests := []struct {
+		path             string
+		expectedStatus   int
+		expectedCode     int
+		expectedEndpoint string
+	}{
+		{"/v2/pnr-status/pnr/2144287856/apikey/WRONG", http.StatusOK, rail.CodeInvalidAPIKey, "PNRStatus"},
+		{"/v2/pnr-status/pnr/2144287856", http.StatusOK, rail.CodeInvalidAPIKey, "PNRStatus"},
+		{"/v2/pnr-status/pnr/123/apikey/KEY", http.StatusOK, rail.CodeInvalidArguments, "PNRStatus"},
+		{"/v2/live/train/12138/date/2018-04-05/apikey/KEY", http.StatusOK, rail.CodeInvalidArguments, "LiveTrainStatus"},
+		{"/v2/unknown/apikey/KEY", http.StatusNotFound, 0, ""},
+	}
+
+	for i, tt := range tests {
+		rsp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatalf("%d. request failed: %s", i, err)
+		}
+
+		var body struct {
+			ResponseCode int `json:"response_code"`
+		}
+		json.NewDecoder(rsp.Body).Decode(&body)
+		rsp.Body.Close()
+
+		if rsp.StatusCode != tt.expectedStatus || body.ResponseCode != tt.expectedCode {
+			t.Errorf("%d. expected: `%d %d`, actual `%d %d`", i, tt.expectedStatus, tt.expectedCode, rsp.StatusCode, body.ResponseCode)
+		}
+
+		calls := srv.Calls()
+		if e := calls[len(calls)-1].Endpoint; e != tt.expectedEndpoint {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.expectedEndpoint, e)
+		}
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	srv := railtest.NewServer("KEY")
+	defer srv.Close()
+
+	tests := []struct {
+		auth     func(rail.Requester) rail.Requester
+		expected error
+	}{
+		{rail.NewAuth("KEY"), nil},
+		{rail.NewHeaderAuth("KEY", ""), nil},
+		{rail.NewQueryAuth("KEY", ""), nil},
+		{rail.NewHeaderAuth("WRONG", ""), rail.ErrInvalidAPIKey},
+		{rail.NewQueryAuth("WRONG", ""), rail.ErrInvalidAPIKey},
+	}
+
+	for i, tt := range tests {
+		c := srv.Client()
+		c.Auth = tt.auth
+
+		_, err := c.PNRStatus(context.Background(), 2144287856)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected, err)
+		}
+	}
+}
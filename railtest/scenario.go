@@ -0,0 +1,530 @@
+package railtest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Clock is a virtual clock driving scenarios. It is safe for use by
+// multiple go routines.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a new Clock set to 't'.
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by 'd', returning the new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set sets the time of the clock to 't'.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Scenario describes trains running and PNRs changing over time, served
+// by Server.Play for LiveTrainStatus, TrainArrivals, RescheduledTrains and
+// PNRStatus calls.
+//
+// Scenarios are usually loaded from YAML or JSON with ParseScenario:
+//
+//	start: 2018-04-04T21:00:00+05:30
+//	trains:
+//	  - number: 12138
+//	    name: PUNJAB MAIL
+//	    stops:
+//	      - {code: FZR, name: FIROZPUR CANT, departure: "21:40"}
+//	      - {code: FDK, name: FARIDKOT, arrival: "22:05", departure: "22:07"}
+//	      - {code: BTI, name: BATHINDA JN, arrival: "00:10", day: 1}
+//	    events:
+//	      - {at: 30m, delay: 15m}
+//	      - {at: 2h, delay: 40m}
+//	pnrs:
+//	  - number: 2144287856
+//	    train: 12138
+//	    from: FDK
+//	    to: BTI
+//	    events:
+//	      - {status: [WL 12, WL 13]}
+//	      - {at: 1h, status: [RAC 4, WL 1]}
+//	      - {at: 2h, chart_prepared: true, status: [CNF/S2/23, CNF/S2/24]}
+type Scenario struct {
+	// Start is the time the scenario starts, from which events are timed.
+	Start time.Time `yaml:"start" json:"start"`
+
+	Trains []Train `yaml:"trains" json:"trains"`
+	PNRs   []PNR   `yaml:"pnrs" json:"pnrs"`
+}
+
+// Train is a train running in a scenario.
+type Train struct {
+	Number uint32 `yaml:"number" json:"number"`
+	Name   string `yaml:"name" json:"name"`
+	// Date is the date the train starts from its source, the date of
+	// Scenario.Start if zero.
+	Date time.Time `yaml:"date" json:"date"`
+	// Stops holds the stops of the train, from its source.
+	Stops []Stop `yaml:"stops" json:"stops"`
+	// Events change the delay of the train over time.
+	Events []Event `yaml:"events" json:"events"`
+}
+
+// Stop is a stop of a train.
+type Stop struct {
+	Code      string  `yaml:"code" json:"code"`
+	Name      string  `yaml:"name" json:"name"`
+	Latitude  float64 `yaml:"lat" json:"lat"`
+	Longitude float64 `yaml:"lng" json:"lng"`
+	// Arrival and Departure are the scheduled times, like "21:40". Arrival
+	// is empty at the source and Departure at the destination.
+	Arrival   string `yaml:"arrival" json:"arrival"`
+	Departure string `yaml:"departure" json:"departure"`
+	// Day is the day of the arrival, counted from 0 on Train.Date.
+	Day      int     `yaml:"day" json:"day"`
+	Distance float64 `yaml:"distance" json:"distance"`
+}
+
+// PNR is a PNR booked on a train of a scenario.
+type PNR struct {
+	Number uint64 `yaml:"number" json:"number"`
+	// Train is the number of the train, which must be in the scenario.
+	Train uint32 `yaml:"train" json:"train"`
+	// From and To are the codes of the stations of the journey.
+	From  string `yaml:"from" json:"from"`
+	To    string `yaml:"to" json:"to"`
+	Class string `yaml:"class" json:"class"`
+	// Events change the status of the passengers over time. The statuses
+	// of the first event are the booking statuses.
+	Events []Event `yaml:"events" json:"events"`
+}
+
+// Event is a change in a scenario. Each event sets the state of its train or
+// PNR from At on; fields not set keep their values from earlier events.
+type Event struct {
+	// At is the time of the event since Scenario.Start.
+	At time.Duration `yaml:"at" json:"at"`
+
+	// Delay is the delay of the train.
+	Delay *time.Duration `yaml:"delay" json:"delay"`
+	// Rescheduled is the time the departure of the train is moved by,
+	// which adds to its delay.
+	Rescheduled *time.Duration `yaml:"rescheduled" json:"rescheduled"`
+
+	// Status holds the current statuses of the passengers of the PNR, like
+	// "WL 12", "RAC 4" or "CNF/S2/23".
+	Status []string `yaml:"status" json:"status"`
+	// ChartPrepared reports whether the chart of the train is prepared.
+	ChartPrepared *bool `yaml:"chart_prepared" json:"chart_prepared"`
+}
+
+// ParseScenario parses a scenario from YAML or JSON 'data'.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var sc Scenario
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, errors.Wrap(err, "unmarshal scenario failed")
+	}
+	return &sc, sc.Validate()
+}
+
+// Validate returns an error if the scenario is invalid.
+func (sc *Scenario) Validate() error {
+	trains := make(map[uint32]Train)
+	for _, tr := range sc.Trains {
+		if len(tr.Stops) < 2 {
+			return errors.Errorf("train %d: expected at least 2 stops", tr.Number)
+		}
+		for i, s := range tr.Stops {
+			for _, c := range []string{s.Arrival, s.Departure} {
+				if _, err := parseClock(c); err != nil {
+					return errors.Wrapf(err, "train %d: stop %d", tr.Number, i)
+				}
+			}
+		}
+		trains[tr.Number] = tr
+	}
+
+	for _, p := range sc.PNRs {
+		tr, ok := trains[p.Train]
+		if !ok {
+			return errors.Errorf("PNR %d: unknown train %d", p.Number, p.Train)
+		}
+		if tr.stop(p.From) == nil || tr.stop(p.To) == nil {
+			return errors.Errorf("PNR %d: stations not in route of train %d", p.Number, p.Train)
+		}
+		if len(p.Events) == 0 || len(p.Events[0].Status) == 0 {
+			return errors.Errorf("PNR %d: expected booking status", p.Number)
+		}
+		for _, e := range p.Events {
+			if e.Status != nil && len(e.Status) != len(p.Events[0].Status) {
+				return errors.Errorf("PNR %d: expected %d statuses at %s", p.Number, len(p.Events[0].Status), e.At)
+			}
+		}
+	}
+	return nil
+}
+
+// Play makes the server respond to LiveTrainStatus, TrainArrivals,
+// RescheduledTrains and PNRStatus calls as defined by scenario 'sc' at the
+// time of 'clock'. Clients returned by Server.Client use 'clock' as their
+// current time.
+func (s *Server) Play(sc *Scenario, clock *Clock) error {
+	if err := sc.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.clock = clock
+	s.mu.Unlock()
+
+	p := player{sc, clock}
+	s.Handle("LiveTrainStatus", p.liveTrainStatus)
+	s.Handle("TrainArrivals", p.trainArrivals)
+	s.Handle("RescheduledTrains", p.rescheduledTrains)
+	s.Handle("PNRStatus", p.pnrStatus)
+	return nil
+}
+
+// player responds to calls as defined by a scenario.
+type player struct {
+	sc    *Scenario
+	clock *Clock
+}
+
+func (p player) liveTrainStatus(call Call) Response {
+	tr := p.train(call.Params["train"])
+	if tr == nil {
+		return Response{Code: rail.CodeNoData}
+	}
+	if formatDate(p.date(tr)) != call.Params["date"] {
+		return Response{Code: rail.CodeTrainNotRunning}
+	}
+
+	now := p.clock.Now()
+	route := make([]map[string]interface{}, len(tr.Stops))
+	var current *Stop
+	for i, st := range tr.Stops {
+		s := p.stop(tr, i, now)
+		if s.arrived {
+			current = &tr.Stops[i]
+		}
+
+		status := fmt.Sprintf("%d mins late", int(s.delay.Minutes()))
+		if s.delay == 0 {
+			status = "On Time"
+		}
+		route[i] = map[string]interface{}{
+			"no":           i + 1,
+			"station":      station(st),
+			"day":          st.Day,
+			"distance":     st.Distance,
+			"scharr":       formatClock(s.schArr, "Source"),
+			"schdep":       formatClock(s.schDep, "Destination"),
+			"actarr":       formatClock(s.actArr, "Source"),
+			"actdep":       formatClock(s.actDep, "Destination"),
+			"scharr_date":  formatDay(s.schArr, s.schDep),
+			"actarr_date":  formatDay(s.actArr, s.actDep),
+			"has_arrived":  s.arrived,
+			"has_departed": s.departed,
+			"latemin":      int(s.delay.Minutes()),
+			"status":       status,
+		}
+	}
+
+	body := map[string]interface{}{
+		"train":      train(tr),
+		"route":      route,
+		"start_date": p.date(tr).Format("2 Jan 2006"),
+		"position":   "Train has not started from its source",
+	}
+	if current != nil {
+		body["current_station"] = station(*current)
+		body["position"] = "Train has reached " + current.Name
+	}
+	return Response{Body: body, Debit: 1}
+}
+
+func (p player) trainArrivals(call Call) Response {
+	hours, _ := strconv.Atoi(call.Params["hours"])
+	now := p.clock.Now()
+	until := now.Add(time.Duration(hours) * time.Hour)
+
+	var trains []map[string]interface{}
+	for _, tr := range p.sc.Trains {
+		for i, st := range tr.Stops {
+			if st.Code != call.Params["station"] || i == 0 {
+				continue
+			}
+
+			s := p.stop(&tr, i, now)
+			if s.actArr.Before(now) || s.actArr.After(until) {
+				continue
+			}
+			trains = append(trains, map[string]interface{}{
+				"number":   strconv.Itoa(int(tr.Number)),
+				"name":     tr.Name,
+				"scharr":   formatClock(s.schArr, "Source"),
+				"schdep":   formatClock(s.schDep, "Destination"),
+				"actarr":   formatClock(s.actArr, "Source"),
+				"actdep":   formatClock(s.actDep, "Destination"),
+				"delayarr": formatHours(s.delay),
+				"delaydep": formatHours(s.delay),
+			})
+		}
+	}
+	return Response{Body: map[string]interface{}{"total": len(trains), "trains": trains}, Debit: 1}
+}
+
+func (p player) rescheduledTrains(call Call) Response {
+	now := p.clock.Now()
+
+	var trains []map[string]interface{}
+	for _, tr := range p.sc.Trains {
+		e := p.event(tr.Events, now)
+		if rail.ValueOr(e.Rescheduled, 0) == 0 || formatDate(p.date(&tr)) != call.Params["date"] {
+			continue
+		}
+
+		dep := p.stop(&tr, 0, now).actDep
+		trains = append(trains, map[string]interface{}{
+			"number":           strconv.Itoa(int(tr.Number)),
+			"name":             tr.Name,
+			"from_station":     station(tr.Stops[0]),
+			"to_station":       station(tr.Stops[len(tr.Stops)-1]),
+			"time_diff":        formatHours(*e.Rescheduled),
+			"rescheduled_date": formatDate(dep),
+			"rescheduled_time": formatClock(dep, ""),
+		})
+	}
+	return Response{Body: map[string]interface{}{"trains": trains}, Debit: 1}
+}
+
+func (p player) pnrStatus(call Call) Response {
+	var pnr *PNR
+	for i := range p.sc.PNRs {
+		if strconv.FormatUint(p.sc.PNRs[i].Number, 10) == call.Params["pnr"] {
+			pnr = &p.sc.PNRs[i]
+		}
+	}
+	if pnr == nil {
+		return Response{Code: rail.CodeInvalidPNR}
+	}
+
+	tr := p.train(strconv.Itoa(int(pnr.Train)))
+	from, to := tr.stop(pnr.From), tr.stop(pnr.To)
+	e := p.event(pnr.Events, p.clock.Now())
+	if e.Status == nil {
+		// Not booked yet.
+		e = pnr.Events[0]
+	}
+
+	passengers := make([]map[string]interface{}, len(e.Status))
+	for i, status := range e.Status {
+		passengers[i] = map[string]interface{}{
+			"no":             i + 1,
+			"booking_status": pnr.Events[0].Status[i],
+			"current_status": status,
+		}
+	}
+
+	return Response{Body: map[string]interface{}{
+		"pnr":              strconv.FormatUint(pnr.Number, 10),
+		"doj":              formatDate(p.date(tr).AddDate(0, 0, from.Day)),
+		"train":            train(tr),
+		"from_station":     station(*from),
+		"boarding_point":   station(*from),
+		"to_station":       station(*to),
+		"reservation_upto": station(*to),
+		"journey_class":    map[string]string{"code": pnr.Class},
+		"chart_prepared":   rail.ValueOr(e.ChartPrepared, false),
+		"total_passengers": len(passengers),
+		"passengers":       passengers,
+	}, Debit: 1}
+}
+
+// train returns the train numbered 'number' in the scenario.
+func (p player) train(number string) *Train {
+	for i, tr := range p.sc.Trains {
+		if strconv.Itoa(int(tr.Number)) == number {
+			return &p.sc.Trains[i]
+		}
+	}
+	return nil
+}
+
+// date returns the date train 'tr' starts from its source.
+func (p player) date(tr *Train) time.Time {
+	d := tr.Date
+	if d.IsZero() {
+		d = p.sc.Start
+	}
+	y, m, dd := d.In(rail.IST).Date()
+	return time.Date(y, m, dd, 0, 0, 0, 0, rail.IST)
+}
+
+// event returns the state set by 'events' occurred at 'now', each
+// overriding the fields set by earlier events, or the zero Event if none.
+func (p player) event(events []Event, now time.Time) Event {
+	sorted := append([]Event(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].At < sorted[j].At })
+
+	var e Event
+	for _, ev := range sorted {
+		if p.sc.Start.Add(ev.At).After(now) {
+			break
+		}
+
+		e.At = ev.At
+		if ev.Delay != nil {
+			e.Delay = ev.Delay
+		}
+		if ev.Rescheduled != nil {
+			e.Rescheduled = ev.Rescheduled
+		}
+		if ev.Status != nil {
+			e.Status = ev.Status
+		}
+		if ev.ChartPrepared != nil {
+			e.ChartPrepared = ev.ChartPrepared
+		}
+	}
+	return e
+}
+
+// stopState is the state of a stop of a train.
+type stopState struct {
+	schArr, schDep, actArr, actDep time.Time // zero if none
+	delay                          time.Duration
+	arrived, departed              bool
+}
+
+// stop returns the state of stop 'i' of train 'tr' at 'now'.
+//
+// The delay at stops passed is the delay when the train reached them, and
+// at the next stops the current delay.
+func (p player) stop(tr *Train, i int, now time.Time) stopState {
+	st := tr.Stops[i]
+	day := p.date(tr).AddDate(0, 0, st.Day)
+
+	var s stopState
+	s.schArr = at(st.Arrival, day, time.Time{})
+	s.schDep = at(st.Departure, day, s.schArr)
+
+	sched := s.schArr
+	if sched.IsZero() {
+		sched = s.schDep
+	}
+	delayAt := func(t time.Time) time.Duration {
+		e := p.event(tr.Events, t)
+		return rail.ValueOr(e.Delay, 0) + rail.ValueOr(e.Rescheduled, 0)
+	}
+	s.delay = delayAt(earliest(sched.Add(delayAt(earliest(sched, now))), now))
+
+	if !s.schArr.IsZero() {
+		s.actArr = s.schArr.Add(s.delay)
+		s.arrived = !s.actArr.After(now)
+	}
+	if !s.schDep.IsZero() {
+		s.actDep = s.schDep.Add(s.delay)
+		s.departed = !s.actDep.After(now)
+		s.arrived = s.arrived || s.departed
+	}
+	return s
+}
+
+// stop returns the stop of the train at station 'code'.
+func (tr *Train) stop(code string) *Stop {
+	for i, s := range tr.Stops {
+		if s.Code == code {
+			return &tr.Stops[i]
+		}
+	}
+	return nil
+}
+
+// parseClock parses a time of day like "21:40". It returns the zero time
+// if 's' is empty.
+func parseClock(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("15:04", s)
+}
+
+// at returns time of day 's' on the date of 'day', rolled over to the next
+// day if it is before 'prev'. It returns the zero time if 's' is empty.
+func at(s string, day time.Time, prev time.Time) time.Time {
+	c, err := parseClock(s)
+	if err != nil || c.IsZero() {
+		return time.Time{}
+	}
+
+	y, m, d := day.Date()
+	t := time.Date(y, m, d, c.Hour(), c.Minute(), 0, 0, rail.IST)
+	if !prev.IsZero() && t.Before(prev) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// formatClock formats time of day 't', or returns 'none' if 't' is zero.
+func formatClock(t time.Time, none string) string {
+	if t.IsZero() {
+		return none
+	}
+	return t.In(rail.IST).Format("15:04")
+}
+
+// formatDay formats the date of 't', or else of 'alt'.
+func formatDay(t, alt time.Time) string {
+	if t.IsZero() {
+		t = alt
+	}
+	return t.In(rail.IST).Format("2 Jan 2006")
+}
+
+// formatDate formats 't' as in request paths.
+func formatDate(t time.Time) string { return t.In(rail.IST).Format("02-01-2006") }
+
+// formatHours formats 'd' in hours and minutes, like "01:05".
+func formatHours(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func station(s Stop) map[string]interface{} {
+	return map[string]interface{}{"code": s.Code, "name": s.Name, "lat": s.Latitude, "lng": s.Longitude}
+}
+
+func train(tr *Train) map[string]interface{} {
+	return map[string]interface{}{"number": strconv.Itoa(int(tr.Number)), "name": tr.Name}
+}
@@ -0,0 +1,161 @@
+package railtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/go-india/rail/railtest"
+	"github.com/pkg/errors"
+)
+
+const journey = `
+start: 2018-04-04T21:00:00+05:30
+trains:
+  - number: 12138
+    name: PUNJAB MAIL
+    stops:
+      - {code: FZR, name: FIROZPUR CANT, departure: "21:40"}
+      - {code: FDK, name: FARIDKOT, arrival: "22:05", departure: "22:07", distance: 32}
+      - {code: BTI, name: BATHINDA JN, arrival: "23:50", departure: "00:05", distance: 120}
+      - {code: BE, name: BAREILLY, arrival: "06:20", day: 1, distance: 700}
+    events:
+      - {at: 30m, delay: 15m}
+      - {at: 2h, delay: 40m}
+      - {at: 4h, rescheduled: 1h}
+pnrs:
+  - number: 2144287856
+    train: 12138
+    from: FDK
+    to: BE
+    class: SL
+    events:
+      - {status: [WL 12, WL 13]}
+      - {at: 1h, status: [RAC 4, WL 1]}
+      - {at: 3h, chart_prepared: true, status: [CNF/S2/23, RAC 2]}
+      - {at: 5h, status: [CNF/S2/23, CNF/S2/24]}
+`
+
+func TestServerPlay(t *testing.T) {
+	sc, err := railtest.ParseScenario([]byte(journey))
+	if err != nil {
+		t.Fatal("ParseScenario failed:", err)
+	}
+
+	srv := railtest.NewServer("KEY")
+	defer srv.Close()
+
+	clock := railtest.NewClock(sc.Start)
+	if err := srv.Play(sc, clock); err != nil {
+		t.Fatal("Play failed:", err)
+	}
+
+	c := srv.Client()
+	ctx := context.Background()
+	d := time.Date(2018, time.April, 4, 0, 0, 0, 0, rail.IST)
+
+	tests := []struct {
+		advance time.Duration
+
+		expectedLate     int
+		expectedDeparted []bool
+		expectedArrival  string // at BE
+		expectedStatus   []string
+		expectedChart    bool
+		expectedArrivals int // at BTI, within 2 hours
+	}{
+		{0, 0, []bool{false, false, false, false}, "06:20", []string{"WL 12", "WL 13"}, false, 0},
+		{90 * time.Minute, 15, []bool{true, true, false, false}, "06:35", []string{"RAC 4", "WL 1"}, false, 1},
+		{90 * time.Minute, 40, []bool{true, true, false, false}, "07:00", []string{"CNF/S2/23", "RAC 2"}, true, 1},
+		{3 * time.Hour, 100, []bool{true, true, true, false}, "08:00", []string{"CNF/S2/23", "CNF/S2/24"}, true, 0},
+	}
+
+	for i, tt := range tests {
+		clock.Advance(tt.advance)
+
+		live, err := c.LiveTrainStatus(ctx, 12138, d)
+		if err != nil {
+			t.Fatalf("%d. LiveTrainStatus failed: %s", i, err)
+		}
+		last := live.Route[len(live.Route)-1]
+		if late := rail.ValueOr(last.LateByMinutes, -1); late != tt.expectedLate {
+			t.Errorf("%d. expected late: `%d`, actual `%d`", i, tt.expectedLate, late)
+		}
+		if a := last.ActualArrivalTime.Format("15:04"); a != tt.expectedArrival {
+			t.Errorf("%d. expected arrival: `%s`, actual `%s`", i, tt.expectedArrival, a)
+		}
+		for j, r := range live.Route {
+			if departed := rail.ValueOr(r.HasDeparted, false); departed != tt.expectedDeparted[j] {
+				t.Errorf("%d. expected departed from stop %d: %t, actual %t", i, j, tt.expectedDeparted[j], departed)
+			}
+		}
+
+		pnr, err := c.PNRStatus(ctx, 2144287856)
+		if err != nil {
+			t.Fatalf("%d. PNRStatus failed: %s", i, err)
+		}
+		if rail.ValueOr(pnr.ChartPrepared, false) != tt.expectedChart {
+			t.Errorf("%d. expected chart prepared: %t", i, tt.expectedChart)
+		}
+		for j, p := range pnr.Passengers {
+			if rail.ValueOr(p.CurrentStatus, "") != tt.expectedStatus[j] {
+				t.Errorf("%d. expected status: `%s`, actual `%s`", i, tt.expectedStatus[j], rail.ValueOr(p.CurrentStatus, ""))
+			}
+			if rail.ValueOr(p.BookingStatus, "") != []string{"WL 12", "WL 13"}[j] {
+				t.Errorf("%d. unexpected booking status: `%s`", i, rail.ValueOr(p.BookingStatus, ""))
+			}
+		}
+
+		arrivals, err := c.TrainArrivals(ctx, "BTI", rail.WindowHour2)
+		if err != nil {
+			t.Fatalf("%d. TrainArrivals failed: %s", i, err)
+		}
+		if len(arrivals.Trains) != tt.expectedArrivals {
+			t.Errorf("%d. expected %d arrivals, actual %d", i, tt.expectedArrivals, len(arrivals.Trains))
+		}
+		// Times are anchored on the clock of the scenario.
+		for _, tr := range arrivals.Trains {
+			if a := tr.ScheduledArrivalTime; a == nil || a.Sub(clock.Now()).Abs() > 2*time.Hour {
+				t.Errorf("%d. expected arrival near `%v`, actual `%v`", i, clock.Now(), a)
+			}
+		}
+	}
+
+	// Train is rescheduled after 4 hours.
+	r, err := c.RescheduledTrains(ctx, d)
+	if err != nil {
+		t.Fatal("RescheduledTrains failed:", err)
+	}
+	if len(r.Trains) != 1 || rail.ValueOr(r.Trains[0].TimeDifference, 0) != time.Hour {
+		t.Errorf("unexpected rescheduled trains: %+v", r.Trains)
+	}
+
+	if _, err := c.LiveTrainStatus(ctx, 12138, d.AddDate(0, 0, 1)); !errors.Is(err, rail.ErrTrainNotRunning) {
+		t.Errorf("expected: `%v`, actual `%v`", rail.ErrTrainNotRunning, err)
+	}
+	if _, err := c.PNRStatus(ctx, 1234567890); !errors.Is(err, rail.ErrInvalidPNR) {
+		t.Errorf("expected: `%v`, actual `%v`", rail.ErrInvalidPNR, err)
+	}
+}
+
+func TestParseScenario(t *testing.T) {
+	tests := []struct {
+		input string
+		err   bool
+	}{
+		{journey, false},
+		{`{"start": "2018-04-04T21:00:00+05:30", "trains": [{"number": 1, "stops": [{"code": "A", "departure": "10:00"}, {"code": "B", "arrival": "11:00"}], "events": [{"at": "30m", "delay": "5m"}]}]}`, false},
+		{`{"trains": [{"number": 1, "stops": [{"code": "A", "departure": "25:00"}, {"code": "B"}]}]}`, true},
+		{`{"trains": [{"number": 1, "stops": [{"code": "A"}]}]}`, true},
+		{`{"trains": [{"number": 1, "stops": [{"code": "A"}, {"code": "B"}]}], "pnrs": [{"number": 1, "train": 2}]}`, true},
+		{`{"trains": [{"number": 1, "stops": [{"code": "A"}, {"code": "B"}]}], "pnrs": [{"number": 1, "train": 1, "from": "A", "to": "B"}]}`, true},
+	}
+
+	for i, tt := range tests {
+		_, err := railtest.ParseScenario([]byte(tt.input))
+		if (err != nil) != tt.err {
+			t.Errorf("%d. expected error: %t, actual `%v`", i, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package rail
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMaxAttempts is the default maximum number of attempts made by
+	// RetryPolicy.
+	DefaultMaxAttempts = 3
+	// DefaultMinBackoff is the default delay before the first retry.
+	DefaultMinBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum delay between retries.
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy defines how Client retries failed requests.
+//
+// Its zero value is usable policy that makes DefaultMaxAttempts attempts
+// with exponential backoff between DefaultMinBackoff and DefaultMaxBackoff.
+// Requests for which credits were debited are never retried unless
+// RetryDebited is set.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a request,
+	// including the first one.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry.
+	// It is doubled after every retry.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries.
+	MaxBackoff time.Duration
+
+	// Retryable reports whether a request which failed with 'err' should
+	// be retried. If nil, IsRetryable is used.
+	Retryable func(err error) bool
+
+	// RetryDebited allows retrying requests for which the API has
+	// debited credits.
+	RetryDebited bool
+}
+
+// retry reports whether a request which failed with 'err' after 'attempt'
+// attempts should be retried.
+func (p RetryPolicy) retry(attempt int, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	max := p.MaxAttempts
+	if max == 0 {
+		max = DefaultMaxAttempts
+	}
+	if attempt >= max {
+		return false
+	}
+
+	var rerr ErrResponseCode
+	if errors.As(err, &rerr) && rerr.Debit > 0 && !p.RetryDebited {
+		return false
+	}
+
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns the delay before the next attempt, after 'attempt' attempts.
+//
+// It uses exponential backoff with full jitter.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = DefaultMinBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+
+	d := min
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// wait sleeps for the backoff delay, returning early if 'ctx' is done.
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	t := time.NewTimer(p.backoff(attempt))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// IsRetryable reports whether a request which failed with 'err' may
+// succeed if sent again.
+//
+// Errors having a Retryable method, like ErrAPI and ErrResponseCode,
+// are classified using it. Network errors are retryable, unless caused
+// by a cancelled context.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
@@ -0,0 +1,191 @@
+package rail_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+// sequenceTransport responds with the next status and body on every request,
+// repeating the last one when exhausted.
+type sequenceTransport struct {
+	statuses []int
+	bodies   []string
+	calls    int
+}
+
+func (st *sequenceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	i := st.calls
+	if i >= len(st.bodies) {
+		i = len(st.bodies) - 1
+	}
+	st.calls++
+
+	status := http.StatusOK
+	if i < len(st.statuses) && st.statuses[i] != 0 {
+		status = st.statuses[i]
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(strings.NewReader(st.bodies[i])),
+		Request:    r,
+	}, nil
+}
+
+func TestRetryPolicy(t *testing.T) {
+	const (
+		ok          = `{"response_code": 200, "debit": 1, "pnr": "2144287856"}`
+		unavailable = `{"response_code": 405, "debit": 0}`
+		debited     = `{"response_code": 405, "debit": 1}`
+		invalid     = `{"response_code": 221, "debit": 0}`
+	)
+
+	tests := []struct {
+		policy   *rail.RetryPolicy
+		statuses []int
+		bodies   []string
+
+		expected error
+		calls    int
+	}{
+		{
+			policy: nil,
+			bodies: []string{unavailable, ok},
+
+			expected: rail.ErrServiceUnavailable,
+			calls:    1,
+		},
+		{
+			policy: &rail.RetryPolicy{MinBackoff: time.Millisecond},
+			bodies: []string{unavailable, unavailable, ok},
+			calls:  3,
+		},
+		{
+			policy: &rail.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond},
+			bodies: []string{unavailable, unavailable, ok},
+
+			expected: rail.ErrServiceUnavailable,
+			calls:    2,
+		},
+		{
+			policy: &rail.RetryPolicy{MinBackoff: time.Millisecond},
+			bodies: []string{invalid, ok},
+
+			expected: rail.ErrInvalidPNR,
+			calls:    1,
+		},
+		{
+			policy: &rail.RetryPolicy{MinBackoff: time.Millisecond},
+			bodies: []string{debited, ok},
+
+			expected: rail.ErrServiceUnavailable,
+			calls:    1,
+		},
+		{
+			policy: &rail.RetryPolicy{MinBackoff: time.Millisecond, RetryDebited: true},
+			bodies: []string{debited, ok},
+			calls:  2,
+		},
+		{
+			policy:   &rail.RetryPolicy{MinBackoff: time.Millisecond},
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			bodies:   []string{"", ok},
+			calls:    2,
+		},
+		{
+			policy:   &rail.RetryPolicy{MinBackoff: time.Millisecond},
+			statuses: []int{http.StatusForbidden, http.StatusOK},
+			bodies:   []string{"", ok},
+
+			expected: rail.ErrAPI{},
+			calls:    1,
+		},
+		{
+			policy: &rail.RetryPolicy{
+				MinBackoff: time.Millisecond,
+				Retryable:  func(err error) bool { return errors.Is(err, rail.ErrInvalidPNR) },
+			},
+			bodies: []string{invalid, ok},
+			calls:  2,
+		},
+	}
+
+	for i, tt := range tests {
+		st := &sequenceTransport{statuses: tt.statuses, bodies: tt.bodies}
+
+		c := rail.NewClient(getAPIKey())
+		c.HTTPClient = &http.Client{Transport: st}
+		c.Retry = tt.policy
+
+		resp, err := c.PNRStatus(context.Background(), 2144287856)
+		switch expected := tt.expected.(type) {
+		case nil:
+			if err != nil {
+				t.Fatalf("test %d: unexpected error: %s", i, err)
+			}
+			if resp.PNR == nil || resp.ResponseCode != 200 {
+				t.Fatalf("test %d: invalid response", i)
+			}
+		case rail.ErrAPI:
+			if !errors.As(err, &expected) {
+				t.Fatalf("test %d: expected ErrAPI, actual `%v`", i, err)
+			}
+		default:
+			if !errors.Is(err, expected) {
+				t.Fatalf("test %d: expected: `%v`, actual `%v`", i, expected, err)
+			}
+		}
+
+		if st.calls != tt.calls {
+			t.Fatalf("test %d: expected %d calls, actual %d", i, tt.calls, st.calls)
+		}
+	}
+}
+
+func TestRetryPolicyContext(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{`{"response_code": 405}`}}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: st}
+	c.Retry = &rail.RetryPolicy{MaxAttempts: 10, MinBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.PNRStatus(ctx, 2144287856)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected: `%v`, actual `%v`", context.DeadlineExceeded, err)
+	}
+	if st.calls != 1 {
+		t.Fatalf("expected 1 call, actual %d", st.calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		input    error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{rail.ErrServiceUnavailable, true},
+		{errors.Wrap(rail.ErrServiceUnavailable, "wrapped"), true},
+		{rail.ErrInvalidAPIKey, false},
+		{context.Canceled, false},
+		{rail.ErrAPI{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}, true},
+		{rail.ErrAPI{Response: &http.Response{StatusCode: http.StatusTooManyRequests}}, true},
+		{rail.ErrAPI{Response: &http.Response{StatusCode: http.StatusNotFound}}, false},
+	}
+
+	for _, tt := range tests {
+		if output := rail.IsRetryable(tt.input); output != tt.expected {
+			t.Fatalf("input `%v`: expected: %t, actual %t", tt.input, tt.expected, output)
+		}
+	}
+}
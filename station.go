@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -28,7 +27,7 @@ func (r TrainBetweenStationsReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/between"
+	urlStr := "v2/between"
 	urlStr += fmt.Sprintf(
 		"/source/%s/dest/%s/date/%s",
 		r.FromStationCode,
@@ -45,9 +44,9 @@ type ExtendedTrain struct {
 
 	ToStation              *Station       `json:"to_station,omitempty"`
 	FromStation            *Station       `json:"from_station,omitempty"`
-	SourceDepartureTime    *time.Time     // `json:"src_departure_time,omitempty"`
-	DestinationArrivalTime *time.Time     // `json:"dest_arrival_time,omitempty"`
-	TravelDuration         *time.Duration // `json:"travel_time,omitempty"`
+	SourceDepartureTime    *time.Time     `json:"-"` // src_departure_time
+	DestinationArrivalTime *time.Time     `json:"-"` // dest_arrival_time
+	TravelDuration         *time.Duration `json:"-"` // travel_time
 }
 
 // UnmarshalJSON convert JSON data to struct
@@ -59,40 +58,44 @@ func (et *ExtendedTrain) UnmarshalJSON(data []byte) error {
 		DestinationArrivalTime string `json:"dest_arrival_time"`
 		TravelTime             string `json:"travel_time"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 
 	*et = ExtendedTrain(t.Alias)
 
-	if len(t.SourceDepartureTime) == 5 {
-		sdt, err := time.Parse("15:04", t.SourceDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse SourceDepartureTime failed")
-		}
-		et.SourceDepartureTime = &sdt
+	if et.SourceDepartureTime, err = parseClock("src_departure_time", t.SourceDepartureTime); err != nil {
+		return err
 	}
 
-	if len(t.DestinationArrivalTime) == 5 {
-		dat, err := time.Parse("15:04", t.DestinationArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse DestinationArrivalTime failed")
-		}
-		et.DestinationArrivalTime = &dat
+	if et.DestinationArrivalTime, err = parseClock("dest_arrival_time", t.DestinationArrivalTime); err != nil {
+		return err
 	}
 
-	if len(t.TravelTime) == 5 {
-		d := func(s string) string { return strings.Replace(s, ":", "h", -1) + "m" }
-		dur, err := time.ParseDuration(d(t.TravelTime))
-		if err != nil {
-			return errors.Wrap(err, "parse travelTime failed")
-		}
-		et.TravelDuration = &dur
+	if et.TravelDuration, err = parseHours("travel_time", t.TravelTime); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (et ExtendedTrain) MarshalJSON() ([]byte, error) {
+	type Alias ExtendedTrain
+	return json.Marshal(struct {
+		Alias
+		SourceDepartureTime    string `json:"src_departure_time,omitempty"`
+		DestinationArrivalTime string `json:"dest_arrival_time,omitempty"`
+		TravelTime             string `json:"travel_time,omitempty"`
+	}{
+		Alias:                  Alias(et),
+		SourceDepartureTime:    formatClock(et.SourceDepartureTime),
+		DestinationArrivalTime: formatClock(et.DestinationArrivalTime),
+		TravelTime:             formatHours(et.TravelDuration),
+	})
+}
+
 // TrainBetweenStationsResp holds trains between stations
 type TrainBetweenStationsResp struct {
 	Trains []ExtendedTrain `json:"trains,omitempty"`
@@ -101,7 +104,18 @@ type TrainBetweenStationsResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r TrainBetweenStationsResp) MarshalJSON() ([]byte, error) {
+	type Alias TrainBetweenStationsResp
+	return replay(r, r.Response, func(r TrainBetweenStationsResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // TrainBetweenStations gets trains running between stations.
+//
+// Times of the trains are anchored on Date.
 func (c Client) TrainBetweenStations(ctx context.Context,
 	FromStationCode string,
 	ToStationCode string,
@@ -117,6 +131,7 @@ func (c Client) TrainBetweenStations(ctx context.Context,
 		ToStationCode:   ToStationCode,
 		Date:            Date,
 	})), &r)
+	r.Anchor(Date)
 	return r, errors.Wrap(err, "Client.Do failed")
 }
 
@@ -148,22 +163,24 @@ func (r TrainArrivalsReq) Request() (*http.Request, error) {
 		return nil, errors.New("invalid WindowHour")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/arrivals"
+	urlStr := "v2/arrivals"
 	urlStr += fmt.Sprintf("/station/%s/hours/%d", r.StationCode, hours)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
 }
 
 // TrainWithTimings holds train timings
+//
+// Delays are durations sent as times of day on the zero date.
 type TrainWithTimings struct {
 	*Train
 
-	DelayArrivalTime       *time.Time //`json:"delayarr,omitempty"`
-	DelayDepartureTime     *time.Time //`json:"delaydep,omitempty"`
-	ScheduledArrivalTime   *time.Time //`json:"scharr,omitempty"`
-	ScheduledDepartureTime *time.Time //`json:"schdep,omitempty"`
-	ActualDepartureTime    *time.Time //`json:"actdep,omitempty"`
-	ActualArrivalTime      *time.Time //`json:"actarr,omitempty"`
+	DelayArrivalTime       *time.Time `json:"-"` // delayarr
+	DelayDepartureTime     *time.Time `json:"-"` // delaydep
+	ScheduledArrivalTime   *time.Time `json:"-"` // scharr
+	ScheduledDepartureTime *time.Time `json:"-"` // schdep
+	ActualDepartureTime    *time.Time `json:"-"` // actdep
+	ActualArrivalTime      *time.Time `json:"-"` // actarr
 }
 
 // UnmarshalJSON convert JSON data to struct
@@ -179,63 +196,65 @@ func (r *TrainWithTimings) UnmarshalJSON(data []byte) error {
 
 		*Train
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 
 	r.Train = t.Train
 
-	if len(t.ScheduledArrivalTime) == 5 {
-		sa, err := time.Parse("15:04", t.ScheduledArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ScheduledArrival failed")
-		}
-		r.ScheduledArrivalTime = &sa
+	if r.ScheduledArrivalTime, err = parseClock("scharr", t.ScheduledArrivalTime); err != nil {
+		return err
 	}
 
-	if len(t.ScheduledDepartureTime) == 5 {
-		sd, err := time.Parse("15:04", t.ScheduledDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ScheduledDeparture failed")
-		}
-		r.ScheduledDepartureTime = &sd
+	if r.ScheduledDepartureTime, err = parseClock("schdep", t.ScheduledDepartureTime); err != nil {
+		return err
 	}
 
-	if len(t.ActualDepartureTime) == 5 {
-		ad, err := time.Parse("15:04", t.ActualDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ActualDeparture failed")
-		}
-		r.ActualDepartureTime = &ad
+	if r.ActualDepartureTime, err = parseClock("actdep", t.ActualDepartureTime); err != nil {
+		return err
 	}
 
-	if len(t.ActualArrivalTime) == 5 {
-		aa, err := time.Parse("15:04", t.ActualArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse ActualArrival failed")
-		}
-		r.ActualArrivalTime = &aa
+	if r.ActualArrivalTime, err = parseClock("actarr", t.ActualArrivalTime); err != nil {
+		return err
 	}
 
-	if len(t.DelayArrivalTime) == 5 {
-		da, err := time.Parse("15:04", t.DelayArrivalTime)
-		if err != nil {
-			return errors.Wrap(err, "parse DelayArrival failed")
-		}
-		r.DelayArrivalTime = &da
+	if r.DelayArrivalTime, err = parseClock("delayarr", t.DelayArrivalTime); err != nil {
+		return err
 	}
 
-	if len(t.DelayDepartureTime) == 5 {
-		dd, err := time.Parse("15:04", t.DelayDepartureTime)
-		if err != nil {
-			return errors.Wrap(err, "parse DelayDeparture failed")
-		}
-		r.DelayDepartureTime = &dd
+	if r.DelayDepartureTime, err = parseClock("delaydep", t.DelayDepartureTime); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (r TrainWithTimings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ScheduledArrivalTime   string `json:"scharr,omitempty"`
+		ScheduledDepartureTime string `json:"schdep,omitempty"`
+		ActualDepartureTime    string `json:"actdep,omitempty"`
+		ActualArrivalTime      string `json:"actarr,omitempty"`
+
+		DelayArrivalTime   string `json:"delayarr,omitempty"`
+		DelayDepartureTime string `json:"delaydep,omitempty"`
+
+		*Train
+	}{
+		ScheduledArrivalTime:   formatClock(r.ScheduledArrivalTime),
+		ScheduledDepartureTime: formatClock(r.ScheduledDepartureTime),
+		ActualDepartureTime:    formatClock(r.ActualDepartureTime),
+		ActualArrivalTime:      formatClock(r.ActualArrivalTime),
+
+		DelayArrivalTime:   formatClock(r.DelayArrivalTime),
+		DelayDepartureTime: formatClock(r.DelayDepartureTime),
+
+		Train: r.Train,
+	})
+}
+
 // TrainArrivalsResp holds train arrivals details
 type TrainArrivalsResp struct {
 	Trains []TrainWithTimings `json:"trains,omitempty"`
@@ -244,10 +263,20 @@ type TrainArrivalsResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r TrainArrivalsResp) MarshalJSON() ([]byte, error) {
+	type Alias TrainArrivalsResp
+	return replay(r, r.Response, func(r TrainArrivalsResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // TrainArrivals get list of trains arriving at a station within
 // a window period along with their live status.
 //
-// Window time in hours to search, valid values are 2 or 4.
+// Window time in hours to search, valid values are 2 or 4. Times of the
+// trains are anchored on the current time, as returned by Client.Now.
 func (c Client) TrainArrivals(ctx context.Context,
 	StationCode string,
 	Hours WindowHour,
@@ -261,6 +290,7 @@ func (c Client) TrainArrivals(ctx context.Context,
 		StationCode: StationCode,
 		Hours:       Hours,
 	})), &r)
+	r.Anchor(c.now())
 	return r, errors.Wrap(err, "Client.Do failed")
 }
 
@@ -277,7 +307,7 @@ func (r StationNameToCodeReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/name-to-code"
+	urlStr := "v2/name-to-code"
 	urlStr += fmt.Sprintf("/station/%s", r.StationName)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -289,6 +319,15 @@ type Stations struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r Stations) MarshalJSON() ([]byte, error) {
+	type Alias Stations
+	return replay(r, r.Response, func(r Stations) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // StationNameToCode gets station details of the given station and
 // its nearby stations using partial station name.
 // Station’s name is autocompleted.
@@ -315,7 +354,7 @@ func (r StationCodeToNameReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/code-to-name"
+	urlStr := "v2/code-to-name"
 	urlStr += fmt.Sprintf("/code/%s", r.StationCode)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -347,7 +386,7 @@ func (r SuggestStationReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/suggest-station"
+	urlStr := "v2/suggest-station"
 	urlStr += fmt.Sprintf("/name/%s", r.StationName)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
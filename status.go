@@ -25,7 +25,7 @@ func (r LiveTrainStatusReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/live"
+	urlStr := "v2/live"
 
 	urlStr += fmt.Sprintf(
 		"/train/%d/date/%s",
@@ -41,7 +41,7 @@ type LiveTrainStatusResp struct {
 	Train          *Train     `json:"train,omitempty"`
 	CurrentStation *Station   `json:"current_station,omitempty"`
 	Route          []Route    `json:"route,omitempty"`
-	StartDate      *time.Time // `json:"start_date,omitempty"`
+	StartDate      *time.Time `json:"-"` // start_date
 	PositionRemark *string    `json:"position,omitempty"`
 
 	*Response
@@ -54,22 +54,38 @@ func (s *LiveTrainStatusResp) UnmarshalJSON(data []byte) error {
 		Alias
 		Start string `json:"start_date"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 	*s = LiveTrainStatusResp(t.Alias)
 
-	if t.Start != "" {
-		start, err := time.Parse("2 Jan 2006", t.Start)
-		if err != nil {
-			return errors.Wrap(err, "parse StartDate failed")
-		}
-		s.StartDate = &start
+	if s.StartDate, err = parseDate("start_date", t.Start); err != nil {
+		return err
+	}
+
+	if s.StartDate != nil {
+		anchorRoute(s.Route, *s.StartDate)
 	}
 
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (s LiveTrainStatusResp) MarshalJSON() ([]byte, error) {
+	return replay(s, s.Response, func(s LiveTrainStatusResp) ([]byte, error) {
+		type Alias LiveTrainStatusResp
+		return json.Marshal(struct {
+			Alias
+			Start string `json:"start_date,omitempty"`
+		}{
+			Alias: Alias(s),
+			Start: formatDate(s.StartDate, "2 Jan 2006"),
+		})
+	})
+}
+
 // LiveTrainStatus gets live running status of a Train.
 func (c Client) LiveTrainStatus(ctx context.Context,
 	TrainNumber uint32,
@@ -99,7 +115,7 @@ func (r TrainRouteReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/route"
+	urlStr := "v2/route"
 	urlStr += fmt.Sprintf("/train/%d", r.TrainNumber)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -113,7 +129,19 @@ type TrainRouteResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r TrainRouteResp) MarshalJSON() ([]byte, error) {
+	type Alias TrainRouteResp
+	return replay(r, r.Response, func(r TrainRouteResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // TrainRoute gets details about all the stations in the train’s route.
+//
+// Times of the route are times of day until anchored with
+// TrainRouteResp.Anchor.
 func (c Client) TrainRoute(ctx context.Context, TrainNumber uint32) (TrainRouteResp, error) {
 	if c.Auth == nil {
 		return TrainRouteResp{}, ErrNoAuth
@@ -147,7 +175,7 @@ func (r CheckSeatReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/check-seat"
+	urlStr := "v2/check-seat"
 	urlStr += fmt.Sprintf(
 		"/train/%d/source/%s/dest/%s/date/%s/pref/%s/quota/%s",
 		r.TrainNumber,
@@ -173,6 +201,15 @@ type CheckSeatResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r CheckSeatResp) MarshalJSON() ([]byte, error) {
+	type Alias CheckSeatResp
+	return replay(r, r.Response, func(r CheckSeatResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // CheckSeat gets train seat availability.
 func (c Client) CheckSeat(ctx context.Context,
 	TrainNumber uint32,
@@ -210,7 +247,7 @@ func (r PNRStatusReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/pnr-status"
+	urlStr := "v2/pnr-status"
 	urlStr += fmt.Sprintf("/pnr/%d", r.PNRNumber)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -219,7 +256,7 @@ func (r PNRStatusReq) Request() (*http.Request, error) {
 // PNRStatusResp is the response for a PNRReq
 type PNRStatusResp struct {
 	ChartPrepared   *bool       `json:"chart_prepared,omitempty"`
-	DateOfJourney   *time.Time  // `json:"doj,omitempty"`
+	DateOfJourney   *time.Time  `json:"-"` // doj
 	BoardingPoint   *Station    `json:"boarding_point,omitempty"`
 	FromStation     *Station    `json:"from_station,omitempty"`
 	ToStation       *Station    `json:"to_station,omitempty"`
@@ -240,22 +277,34 @@ func (p *PNRStatusResp) UnmarshalJSON(data []byte) error {
 		Alias
 		DOJ string `json:"doj"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 	*p = PNRStatusResp(t.Alias)
 
-	if t.DOJ != "" {
-		doj, err := time.Parse("02-01-2006", t.DOJ)
-		if err != nil {
-			return errors.Wrap(err, "parse DateOfJourney failed")
-		}
-		p.DateOfJourney = &doj
+	if p.DateOfJourney, err = parseDate("doj", t.DOJ); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (p PNRStatusResp) MarshalJSON() ([]byte, error) {
+	return replay(p, p.Response, func(p PNRStatusResp) ([]byte, error) {
+		type Alias PNRStatusResp
+		return json.Marshal(struct {
+			Alias
+			DOJ string `json:"doj,omitempty"`
+		}{
+			Alias: Alias(p),
+			DOJ:   formatDate(p.DateOfJourney, "02-01-2006"),
+		})
+	})
+}
+
 // PNRStatus gets PNR status details.
 func (c Client) PNRStatus(ctx context.Context, PNRNumber uint64) (PNRStatusResp, error) {
 	if c.Auth == nil {
@@ -292,7 +341,7 @@ func (r TrainFareReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/fare"
+	urlStr := "v2/fare"
 	urlStr += fmt.Sprintf(
 		"/train/%d/source/%s/dest/%s/age/%d/pref/%s/quota/%s/date/%s",
 		r.TrainNumber,
@@ -320,6 +369,15 @@ type TrainFareResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r TrainFareResp) MarshalJSON() ([]byte, error) {
+	type Alias TrainFareResp
+	return replay(r, r.Response, func(r TrainFareResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // TrainFare gets fares of a train.
 func (c Client) TrainFare(ctx context.Context,
 	TrainNumber uint32,
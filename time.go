@@ -0,0 +1,125 @@
+package rail
+
+import "time"
+
+// Times of day sent by API, like "scharr", are unmarshalled in IST on the
+// zero date. Anchoring resolves them to instants on the date of the journey,
+// keeping the time of day, so the clock time sent by API remains available
+// through time.Time.Clock.
+
+// at returns time of day 'clock' on the date of 'day'.
+func at(clock *time.Time, day time.Time) *time.Time {
+	if clock == nil {
+		return nil
+	}
+	y, m, d := day.Date()
+	hh, mm, _ := clock.In(IST).Clock()
+	t := time.Date(y, m, d, hh, mm, 0, 0, IST)
+	return &t
+}
+
+// after returns time of day 'clock' on the date of 'day', rolled over to the
+// next day if it is before 'prev'.
+func after(clock *time.Time, day time.Time, prev *time.Time) *time.Time {
+	t := at(clock, day)
+	if t != nil && prev != nil && t.Before(*prev) {
+		*t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// near returns time of day 'clock' on the day nearest to 'ref'.
+func near(clock *time.Time, ref time.Time) *time.Time {
+	t := at(clock, ref.In(IST))
+	switch {
+	case t == nil:
+	case t.Sub(ref) > 12*time.Hour:
+		*t = t.AddDate(0, 0, -1)
+	case ref.Sub(*t) > 12*time.Hour:
+		*t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// hours returns time of day 'clock' as duration since midnight, for values
+// like delays which API sends as "hh:mm".
+func hours(clock *time.Time) time.Duration {
+	h, m, _ := clock.In(IST).Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+}
+
+// anchorRoute resolves the times of 'route' to instants for the journey
+// starting on 'start'. The Day offset of stops is relative to the first stop,
+// as API counts days from 0 or 1 depending on the endpoint.
+func anchorRoute(route []Route, start time.Time) {
+	var first int
+	if len(route) > 0 && route[0].Day != nil {
+		first = *route[0].Day
+	}
+
+	for i := range route {
+		r := &route[i]
+
+		day := start
+		if r.Day != nil {
+			day = start.AddDate(0, 0, *r.Day-first)
+		}
+		if r.ScheduledArrivalDate != nil {
+			day = *r.ScheduledArrivalDate
+		}
+		r.ScheduledArrivalTime = at(r.ScheduledArrivalTime, day)
+		r.ScheduledDepartureTime = after(r.ScheduledDepartureTime, day, r.ScheduledArrivalTime)
+
+		if r.ActualArrivalDate != nil {
+			day = *r.ActualArrivalDate
+		}
+		r.ActualArrivalTime = at(r.ActualArrivalTime, day)
+		r.ActualDepartureTime = after(r.ActualDepartureTime, day, r.ActualArrivalTime)
+	}
+}
+
+// Anchor resolves the times of the route to instants for the journey
+// starting on 'start', rolling stops over to later days by their Day offset.
+func (r *TrainRouteResp) Anchor(start time.Time) {
+	anchorRoute(r.Route, start)
+}
+
+// Anchor resolves the times of the trains to instants for the journey on
+// 'date', the date of the request.
+func (r *TrainBetweenStationsResp) Anchor(date time.Time) {
+	for i := range r.Trains {
+		t := &r.Trains[i]
+
+		t.SourceDepartureTime = at(t.SourceDepartureTime, date)
+		if t.SourceDepartureTime != nil && t.TravelDuration != nil {
+			t.DestinationArrivalTime = near(t.DestinationArrivalTime,
+				t.SourceDepartureTime.Add(*t.TravelDuration))
+		} else {
+			t.DestinationArrivalTime = after(t.DestinationArrivalTime, date, t.SourceDepartureTime)
+		}
+	}
+}
+
+// Anchor resolves the times of the trains to instants nearest to 'now', the
+// time of the request. Scheduled times are resolved against the actual times
+// less the delays, so trains delayed over midnight keep their schedule day.
+func (r *TrainArrivalsResp) Anchor(now time.Time) {
+	for i := range r.Trains {
+		t := &r.Trains[i]
+
+		t.ActualArrivalTime = near(t.ActualArrivalTime, now)
+		t.ActualDepartureTime = near(t.ActualDepartureTime, now)
+
+		ref := now
+		if t.ActualArrivalTime != nil && t.DelayArrivalTime != nil {
+			ref = t.ActualArrivalTime.Add(-hours(t.DelayArrivalTime))
+		}
+		t.ScheduledArrivalTime = near(t.ScheduledArrivalTime, ref)
+
+		ref = now
+		if t.ActualDepartureTime != nil && t.DelayDepartureTime != nil {
+			ref = t.ActualDepartureTime.Add(-hours(t.DelayDepartureTime))
+		}
+		t.ScheduledDepartureTime = near(t.ScheduledDepartureTime, ref)
+	}
+}
@@ -0,0 +1,136 @@
+package rail_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+)
+
+func ist(day, hour, min int) time.Time {
+	return time.Date(2018, time.April, day, hour, min, 0, 0, rail.IST)
+}
+
+func TestAnchor(t *testing.T) {
+	t.Run("LiveTrainStatus", testAnchorLiveTrainStatus)
+	t.Run("TrainRoute", testAnchorTrainRoute)
+	t.Run("TrainBetweenStations", testAnchorTrainBetweenStations)
+	t.Run("TrainArrivals", testAnchorTrainArrivals)
+}
+
+func testAnchorLiveTrainStatus(t *testing.T) {
+	c := rail.NewClient(getAPIKey())
+	testClient(&c, t)
+
+	resp, err := c.LiveTrainStatus(context.Background(), 12138, time.Now())
+	if err != nil {
+		t.Fatal("LiveTrainStatus failed:", err)
+	}
+
+	last := len(resp.Route) - 1
+	tests := []struct {
+		input    *time.Time
+		expected time.Time
+	}{
+		{resp.Route[0].ScheduledDepartureTime, ist(4, 21, 40)},
+		{resp.Route[5].ScheduledArrivalTime, ist(4, 23, 25)},
+		{resp.Route[6].ScheduledArrivalTime, ist(5, 0, 1)},
+		{resp.Route[6].ActualDepartureTime, ist(5, 0, 3)},
+		{resp.Route[last].ScheduledArrivalTime, ist(6, 7, 35)},
+	}
+
+	for i, tt := range tests {
+		if tt.input == nil || !tt.input.Equal(tt.expected) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected, tt.input)
+		}
+	}
+}
+
+func testAnchorTrainRoute(t *testing.T) {
+	c := rail.NewClient(getAPIKey())
+	testClient(&c, t)
+
+	resp, err := c.TrainRoute(context.Background(), 14311)
+	if err != nil {
+		t.Fatal("TrainRoute failed:", err)
+	}
+
+	if d := resp.Route[0].ScheduledDepartureTime; d.Year() != 0 {
+		t.Errorf("expected time of day, actual `%v`", d)
+	}
+
+	resp.Anchor(ist(5, 0, 0))
+	first, last := resp.Route[0], resp.Route[len(resp.Route)-1]
+	if !first.ScheduledDepartureTime.Equal(ist(5, 6, 0)) {
+		t.Errorf("expected: `%v`, actual `%v`", ist(5, 6, 0), first.ScheduledDepartureTime)
+	}
+	if !last.ScheduledArrivalTime.Equal(ist(6, 14, 0)) {
+		t.Errorf("expected: `%v`, actual `%v`", ist(6, 14, 0), last.ScheduledArrivalTime)
+	}
+
+	// Clock time sent by API is kept.
+	if h, m, _ := last.ScheduledArrivalTime.Clock(); h != 14 || m != 0 {
+		t.Errorf("expected clock: 14:00, actual %02d:%02d", h, m)
+	}
+}
+
+func testAnchorTrainBetweenStations(t *testing.T) {
+	c := rail.NewClient(getAPIKey())
+	testClient(&c, t)
+
+	d := time.Date(2018, time.April, 5, 0, 0, 0, 0, time.UTC)
+
+	resp, err := c.TrainBetweenStations(context.Background(), "BE", "ADI", d)
+	if err != nil {
+		t.Fatal("TrainBetweenStations failed:", err)
+	}
+
+	tests := []struct {
+		input    rail.ExtendedTrain
+		expected [2]time.Time
+	}{
+		{resp.Trains[0], [2]time.Time{ist(5, 1, 3), ist(5, 23, 55)}},
+		{resp.Trains[1], [2]time.Time{ist(5, 6, 0), ist(6, 6, 15)}},
+	}
+
+	for i, tt := range tests {
+		if !tt.input.SourceDepartureTime.Equal(tt.expected[0]) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected[0], tt.input.SourceDepartureTime)
+		}
+		if !tt.input.DestinationArrivalTime.Equal(tt.expected[1]) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected[1], tt.input.DestinationArrivalTime)
+		}
+	}
+}
+
+func testAnchorTrainArrivals(t *testing.T) {
+	c := rail.NewClient(getAPIKey())
+	testClient(&c, t)
+	c.Now = func() time.Time { return ist(5, 8, 30) }
+
+	resp, err := c.TrainArrivals(context.Background(), "BE", rail.WindowHour2)
+	if err != nil {
+		t.Fatal("TrainArrivals failed:", err)
+	}
+
+	// Second train arrived at 08:27, 14:17 late for its 18:10 schedule of
+	// the previous day.
+	tr := resp.Trains[1]
+	tests := []struct {
+		input    *time.Time
+		expected time.Time
+	}{
+		{resp.Trains[0].ScheduledArrivalTime, ist(5, 7, 45)},
+		{resp.Trains[0].ActualArrivalTime, ist(5, 8, 19)},
+		{tr.ActualArrivalTime, ist(5, 8, 27)},
+		{tr.ScheduledArrivalTime, ist(4, 18, 10)},
+		{tr.ScheduledDepartureTime, ist(4, 18, 15)},
+	}
+
+	for i, tt := range tests {
+		if tt.input == nil || !tt.input.Equal(tt.expected) {
+			t.Errorf("%d. expected: `%v`, actual `%v`", i, tt.expected, tt.input)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package rail
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Attribute keys of spans started by Client.
+const (
+	AttrEndpoint     = "rail.endpoint"
+	AttrTrainNumber  = "rail.train_number"
+	AttrTrainName    = "rail.train_name"
+	AttrStationCode  = "rail.station_code"
+	AttrStationName  = "rail.station_name"
+	AttrFromStation  = "rail.from_station"
+	AttrToStation    = "rail.to_station"
+	AttrResponseCode = "rail.response_code"
+	AttrDebit        = "rail.debit"
+	AttrCacheHit     = "rail.cache_hit"
+	AttrAttempt      = "rail.attempt"
+	AttrHTTPStatus   = "http.status_code"
+)
+
+// SpanAttempt is the name of spans started by Client for every request
+// sent.
+const SpanAttempt = "rail.attempt"
+
+// Attr is an attribute of a Span.
+type Attr struct {
+	Key string
+	// Value is a string, int, bool or float64.
+	Value interface{}
+}
+
+// Tracer starts spans for API calls of Client.
+//
+// Client starts a span named "rail.<endpoint>", like
+// "rail.TrainBetweenStations", for every call of Do, with a child span
+// named SpanAttempt for every request sent. Spans are started with the
+// context of the request, which carries the context passed to client
+// methods, and the returned context is used for the request.
+//
+// Use package railotel to adapt an OpenTelemetry tracer, and package
+// railprom to collect Prometheus metrics.
+type Tracer interface {
+	// Start starts a span with 'name' and attributes 'attrs'.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is a traced operation started by Tracer.
+type Span interface {
+	// SetAttributes sets attributes of the span.
+	SetAttributes(attrs ...Attr)
+	// End ends the span, recording 'err' if not nil.
+	End(err error)
+}
+
+// noopTracer is a Tracer starting spans which do nothing.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr) {}
+func (noopSpan) End(error)             {}
+
+// MultiTracer returns a Tracer starting spans with all of 'tracers', like
+// for collecting metrics along with tracing.
+func MultiTracer(tracers ...Tracer) Tracer {
+	return multiTracer(tracers)
+}
+
+type multiTracer []Tracer
+
+func (mt multiTracer) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	spans := make(multiSpan, len(mt))
+	for i, t := range mt {
+		ctx, spans[i] = t.Start(ctx, name, attrs...)
+	}
+	return ctx, spans
+}
+
+type multiSpan []Span
+
+func (ms multiSpan) SetAttributes(attrs ...Attr) {
+	for _, s := range ms {
+		s.SetAttributes(attrs...)
+	}
+}
+
+func (ms multiSpan) End(err error) {
+	for _, s := range ms {
+		s.End(err)
+	}
+}
+
+// tracer returns the Tracer of client, or a no-op one if it has none.
+func (c Client) tracer() Tracer {
+	if c.Tracer == nil {
+		return noopTracer{}
+	}
+	return c.Tracer
+}
+
+// endpoint returns the name of the endpoint requested by 'r', like
+// "TrainBetweenStations", or "Do" for unknown Requesters.
+func endpoint(r Requester) string {
+	t := reflect.TypeOf(unwrap(r))
+	if t == nil || t.PkgPath() != reflect.TypeOf(Client{}).PkgPath() ||
+		!strings.HasSuffix(t.Name(), "Req") {
+		return "Do"
+	}
+	return strings.TrimSuffix(t.Name(), "Req")
+}
+
+// requestAttrs returns span attributes of the parameters of 'r'.
+//
+// PNR numbers are not included, as they identify passengers.
+func requestAttrs(r Requester) []Attr {
+	attrs := []Attr{{AttrEndpoint, endpoint(r)}}
+
+	switch r := unwrap(r).(type) {
+	case TrainBetweenStationsReq:
+		attrs = append(attrs, Attr{AttrFromStation, r.FromStationCode}, Attr{AttrToStation, r.ToStationCode})
+	case TrainArrivalsReq:
+		attrs = append(attrs, Attr{AttrStationCode, r.StationCode})
+	case StationNameToCodeReq:
+		attrs = append(attrs, Attr{AttrStationName, r.StationName})
+	case StationCodeToNameReq:
+		attrs = append(attrs, Attr{AttrStationCode, r.StationCode})
+	case SuggestStationReq:
+		attrs = append(attrs, Attr{AttrStationName, r.StationName})
+	case LiveTrainStatusReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainNumber)})
+	case TrainRouteReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainNumber)})
+	case CheckSeatReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainNumber)},
+			Attr{AttrFromStation, r.FromStationCode}, Attr{AttrToStation, r.ToStationCode})
+	case TrainFareReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainNumber)},
+			Attr{AttrFromStation, r.FromStationCode}, Attr{AttrToStation, r.ToStationCode})
+	case TrainByNumberReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainNumber)})
+	case TrainByNameReq:
+		attrs = append(attrs, Attr{AttrTrainName, r.TrainName})
+	case SuggestTrainByNameReq:
+		attrs = append(attrs, Attr{AttrTrainName, r.TrainName})
+	case SuggestTrainByCodeReq:
+		attrs = append(attrs, Attr{AttrTrainNumber, int(r.TrainCode)})
+	}
+	return attrs
+}
+
+// responseAttrs returns span attributes of the response 'rsp' with 'body'.
+func responseAttrs(rsp *http.Response, body []byte) []Attr {
+	var attrs []Attr
+	if rsp != nil {
+		attrs = append(attrs, Attr{AttrHTTPStatus, rsp.StatusCode})
+	}
+	if body != nil {
+		r := envelope(body)
+		attrs = append(attrs, Attr{AttrResponseCode, r.ResponseCode}, Attr{AttrDebit, r.Debit})
+	}
+	return attrs
+}
@@ -0,0 +1,130 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+type ctxKey struct{}
+
+// recordTracer records spans started by it.
+type recordTracer struct {
+	mu    sync.Mutex
+	spans []*recordSpan
+}
+
+type recordSpan struct {
+	name   string
+	parent *recordSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+	ctxVal interface{}
+}
+
+func (rt *recordTracer) Start(ctx context.Context, name string, attrs ...rail.Attr) (context.Context, rail.Span) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	s := &recordSpan{name: name, attrs: make(map[string]interface{}), ctxVal: ctx.Value(ctxKey{})}
+	s.parent, _ = ctx.Value(rt).(*recordSpan)
+	s.SetAttributes(attrs...)
+	rt.spans = append(rt.spans, s)
+	return context.WithValue(ctx, rt, s), s
+}
+
+func (s *recordSpan) SetAttributes(attrs ...rail.Attr) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordSpan) End(err error) { s.err, s.ended = err, true }
+
+func TestClientTracer(t *testing.T) {
+	st := &sequenceTransport{bodies: []string{
+		`{"response_code": 405, "debit": 0}`,
+		`{"response_code": 221, "debit": 1}`,
+	}}
+	rt := &recordTracer{}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: mockTransport(func(r *http.Request) (*http.Response, error) {
+		if r.Context().Value(ctxKey{}) != "value" {
+			t.Error("request context doesn't hold caller's context values")
+		}
+		return st.RoundTrip(r)
+	})}
+	c.Retry = &rail.RetryPolicy{MinBackoff: 1}
+	c.Tracer = rt
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	_, err := c.CheckSeat(ctx, 14311, "BE", "ADI", "SL", "GN", time.Now())
+	if !errors.Is(err, rail.ErrInvalidPNR) {
+		t.Fatal("expected ErrInvalidPNR, got:", err)
+	}
+
+	if len(rt.spans) != 3 {
+		t.Fatalf("expected %d spans, actual %d", 3, len(rt.spans))
+	}
+
+	call := rt.spans[0]
+	if call.name != "rail.CheckSeat" || call.parent != nil || !call.ended || !errors.Is(call.err, rail.ErrInvalidPNR) {
+		t.Errorf("unexpected call span: %+v", call)
+	}
+	if call.ctxVal != "value" {
+		t.Error("span isn't started with caller's context")
+	}
+	expected := map[string]interface{}{
+		rail.AttrEndpoint:     "CheckSeat",
+		rail.AttrTrainNumber:  14311,
+		rail.AttrFromStation:  "BE",
+		rail.AttrToStation:    "ADI",
+		rail.AttrHTTPStatus:   http.StatusOK,
+		rail.AttrResponseCode: 221,
+		rail.AttrDebit:        1,
+	}
+	if !reflect.DeepEqual(call.attrs, expected) {
+		t.Errorf("expected: %v, actual %v", expected, call.attrs)
+	}
+
+	for i, s := range rt.spans[1:] {
+		if s.name != "rail.attempt" || s.parent != call || !s.ended {
+			t.Errorf("%d. unexpected attempt span: %+v", i, s)
+		}
+		if s.attrs[rail.AttrAttempt] != i+1 {
+			t.Errorf("%d. expected attempt: %d, actual %v", i, i+1, s.attrs[rail.AttrAttempt])
+		}
+	}
+	if code := rt.spans[1].attrs[rail.AttrResponseCode]; code != 405 {
+		t.Errorf("expected: `%d`, actual `%v`", 405, code)
+	}
+}
+
+func TestMultiTracer(t *testing.T) {
+	rt1, rt2 := &recordTracer{}, &recordTracer{}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: mockBody(`{"response_code": 200}`)}
+	c.Tracer = rail.MultiTracer(rt1, rt2)
+
+	if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+
+	for i, rt := range []*recordTracer{rt1, rt2} {
+		if len(rt.spans) != 2 {
+			t.Fatalf("%d. expected %d spans, actual %d", i, 2, len(rt.spans))
+		}
+		if rt.spans[1].parent != rt.spans[0] || !rt.spans[0].ended || !rt.spans[1].ended {
+			t.Errorf("%d. unexpected spans: %+v", i, rt.spans)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -23,7 +22,7 @@ func (r TrainByNumberReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/name-number"
+	urlStr := "v2/name-number"
 	urlStr += fmt.Sprintf("/train/%d", r.TrainNumber)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -35,6 +34,15 @@ type TrainResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r TrainResp) MarshalJSON() ([]byte, error) {
+	type Alias TrainResp
+	return replay(r, r.Response, func(r TrainResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // TrainByNumber gets train details by its number.
 func (c Client) TrainByNumber(ctx context.Context, TrainNumber uint32) (TrainResp, error) {
 	if c.Auth == nil {
@@ -58,7 +66,7 @@ func (r TrainByNameReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/name-number"
+	urlStr := "v2/name-number"
 	urlStr += fmt.Sprintf("/train/%s", r.TrainName)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -88,7 +96,7 @@ func (r CancelledTrainsReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/cancelled"
+	urlStr := "v2/cancelled"
 	urlStr += fmt.Sprintf("/date/%s", date(r.Date))
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -99,7 +107,7 @@ type TrainSemi struct {
 	Source      *Station   `json:"source,omitempty"`
 	Destination *Station   `json:"dest,omitempty"`
 	Type        *string    `json:"type,omitempty"`
-	StartDate   *time.Time //`json:"start_time,omitempty"`
+	StartDate   *time.Time `json:"-"` // start_time
 
 	*Train
 }
@@ -111,22 +119,31 @@ func (s *TrainSemi) UnmarshalJSON(data []byte) error {
 		Alias
 		Start string `json:"start_time"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 	*s = TrainSemi(t.Alias)
 
-	if t.Start != "" {
-		start, err := time.Parse("2 Jan 2006", t.Start)
-		if err != nil {
-			return errors.Wrap(err, "parse StartDate failed")
-		}
-		s.StartDate = &start
+	if s.StartDate, err = parseDate("start_time", t.Start); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (s TrainSemi) MarshalJSON() ([]byte, error) {
+	type Alias TrainSemi
+	return json.Marshal(struct {
+		Alias
+		Start string `json:"start_time,omitempty"`
+	}{
+		Alias: Alias(s),
+		Start: formatDate(s.StartDate, "2 Jan 2006"),
+	})
+}
+
 // CancelledTrainsResp holds cancelled trains details
 type CancelledTrainsResp struct {
 	Trains []TrainSemi `json:"trains,omitempty"`
@@ -135,6 +152,15 @@ type CancelledTrainsResp struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r CancelledTrainsResp) MarshalJSON() ([]byte, error) {
+	type Alias CancelledTrainsResp
+	return replay(r, r.Response, func(r CancelledTrainsResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // CancelledTrains gets list of all cancelled trains on a particular day.
 func (c Client) CancelledTrains(ctx context.Context, Date time.Time) (CancelledTrainsResp, error) {
 	if c.Auth == nil {
@@ -159,7 +185,7 @@ func (r RescheduledTrainsReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/rescheduled"
+	urlStr := "v2/rescheduled"
 	urlStr += fmt.Sprintf("/date/%s", date(r.Date))
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -170,9 +196,9 @@ type RescheduledTrain struct {
 	FromStation *Station `json:"from_station,omitempty"`
 	ToStation   *Station `json:"to_station,omitempty"`
 
-	TimeDifference  *time.Duration // `json:"time_diff,omitempty"`
-	RescheduledDate *time.Time     // `json:"rescheduled_date,omitempty"`
-	RescheduledTime *time.Time     // `json:"rescheduled_time,omitempty"`
+	TimeDifference  *time.Duration `json:"-"` // time_diff
+	RescheduledDate *time.Time     `json:"-"` // rescheduled_date
+	RescheduledTime *time.Time     `json:"-"` // rescheduled_time
 
 	*Train
 }
@@ -186,45 +212,62 @@ func (s *RescheduledTrain) UnmarshalJSON(data []byte) error {
 		RescheduledDate string `json:"rescheduled_date"`
 		RescheduledTime string `json:"rescheduled_time"`
 	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
+	err := json.Unmarshal(data, &t)
+	if err != nil {
 		return errors.Wrap(err, "UnmarshalJSON failed")
 	}
 
 	*s = RescheduledTrain(t.Alias)
 
-	if t.RescheduledDate != "" {
-		rd, err := time.Parse("02-01-2006", t.RescheduledDate)
-		if err != nil {
-			return errors.Wrap(err, "parse RescheduledDate failed")
-		}
-		s.RescheduledDate = &rd
+	if s.RescheduledDate, err = parseDate("rescheduled_date", t.RescheduledDate); err != nil {
+		return err
 	}
 
-	if len(t.RescheduledTime) == 5 {
-		rt, err := time.Parse("15:04", t.RescheduledTime)
-		if err != nil {
-			return errors.Wrap(err, "parse RescheduledTime failed")
-		}
-		s.RescheduledTime = &rt
+	if s.RescheduledTime, err = parseClock("rescheduled_time", t.RescheduledTime); err != nil {
+		return err
 	}
 
-	if len(t.TimeDifference) == 5 {
-		d := func(s string) string { return strings.Replace(s, ":", "h", -1) + "m" }
-		dur, err := time.ParseDuration(d(t.TimeDifference))
-		if err != nil {
-			return errors.Wrap(err, "parse TimeDifference failed")
-		}
-		s.TimeDifference = &dur
+	if s.RescheduledDate != nil {
+		s.RescheduledTime = at(s.RescheduledTime, *s.RescheduledDate)
+	}
+
+	if s.TimeDifference, err = parseHours("time_diff", t.TimeDifference); err != nil {
+		return err
 	}
 	return nil
 }
 
+// MarshalJSON convert struct to JSON data in the format of API
+func (s RescheduledTrain) MarshalJSON() ([]byte, error) {
+	type Alias RescheduledTrain
+	return json.Marshal(struct {
+		Alias
+		TimeDifference  string `json:"time_diff,omitempty"`
+		RescheduledDate string `json:"rescheduled_date,omitempty"`
+		RescheduledTime string `json:"rescheduled_time,omitempty"`
+	}{
+		Alias:           Alias(s),
+		TimeDifference:  formatHours(s.TimeDifference),
+		RescheduledDate: formatDate(s.RescheduledDate, "02-01-2006"),
+		RescheduledTime: formatClock(s.RescheduledTime),
+	})
+}
+
 // RescheduledTrainsResp holds rescheduled trains
 type RescheduledTrainsResp struct {
 	Trains []RescheduledTrain `json:"trains,omitempty"`
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r RescheduledTrainsResp) MarshalJSON() ([]byte, error) {
+	type Alias RescheduledTrainsResp
+	return replay(r, r.Response, func(r RescheduledTrainsResp) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // RescheduledTrains gets list of all rescheduled trains on a particular date.
 func (c Client) RescheduledTrains(ctx context.Context, Date time.Time) (RescheduledTrainsResp, error) {
 	if c.Auth == nil {
@@ -249,7 +292,7 @@ func (r SuggestTrainByNameReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/suggest-train"
+	urlStr := "v2/suggest-train"
 	urlStr += fmt.Sprintf("/train/%s", r.TrainName)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -261,6 +304,15 @@ type Trains struct {
 	*Response
 }
 
+// MarshalJSON convert struct to JSON data in the format of API, replaying
+// the response as sent by API where unchanged.
+func (r Trains) MarshalJSON() ([]byte, error) {
+	type Alias Trains
+	return replay(r, r.Response, func(r Trains) ([]byte, error) {
+		return json.Marshal(Alias(r))
+	})
+}
+
 // SuggestTrainByName suggests full train names or numbers given a partial train name.
 func (c Client) SuggestTrainByName(ctx context.Context, TrainName string) (Trains, error) {
 	if c.Auth == nil {
@@ -285,7 +337,7 @@ func (r SuggestTrainByCodeReq) Request() (*http.Request, error) {
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	urlStr := DefaultBaseURL + "/v2/suggest-train"
+	urlStr := "v2/suggest-train"
 	urlStr += fmt.Sprintf("/train/%d", r.TrainCode)
 
 	return http.NewRequest(http.MethodGet, urlStr, nil)
@@ -0,0 +1,170 @@
+package rail
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the default timeout of a request sent by Client without
+// an HTTPClient.
+const DefaultTimeout = 15 * time.Second
+
+// EndpointClass classifies API endpoints by how often their data changes
+// and how long they take to respond.
+type EndpointClass uint8
+
+const (
+	// UnknownEndpoint is the class of unknown Requesters.
+	UnknownEndpoint EndpointClass = iota
+	// StaticEndpoint is the class of endpoints of data which rarely change,
+	// like train routes and station names.
+	StaticEndpoint
+	// ScheduleEndpoint is the class of endpoints of schedules, fares and
+	// cancelled or rescheduled trains.
+	ScheduleEndpoint
+	// LiveEndpoint is the class of endpoints of live data, like live train
+	// status and seat availability.
+	LiveEndpoint
+	// PNREndpoint is the class of PNR status endpoint.
+	PNREndpoint
+)
+
+// String returns the name of the class.
+func (ec EndpointClass) String() string {
+	switch ec {
+	case StaticEndpoint:
+		return "static"
+	case ScheduleEndpoint:
+		return "schedule"
+	case LiveEndpoint:
+		return "live"
+	case PNREndpoint:
+		return "pnr"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassOf returns the class of endpoint requested by 'r'.
+//
+// Requesters wrapping another one, like the ones returned by
+// RequestMiddleware, must implement an Unwrap method returning the wrapped
+// Requester for it to be known. Client falls back to the class of the
+// request path for Requesters it doesn't know.
+func ClassOf(r Requester) EndpointClass {
+	switch unwrap(r).(type) {
+	case TrainRouteReq,
+		TrainByNumberReq,
+		TrainByNameReq,
+		StationNameToCodeReq,
+		StationCodeToNameReq,
+		SuggestStationReq,
+		SuggestTrainByNameReq,
+		SuggestTrainByCodeReq:
+		return StaticEndpoint
+	case TrainBetweenStationsReq,
+		TrainFareReq,
+		CancelledTrainsReq,
+		RescheduledTrainsReq:
+		return ScheduleEndpoint
+	case LiveTrainStatusReq,
+		TrainArrivalsReq,
+		CheckSeatReq:
+		return LiveEndpoint
+	case PNRStatusReq:
+		return PNREndpoint
+	default:
+		return UnknownEndpoint
+	}
+}
+
+// pathClasses holds the classes of endpoints by the path segment following
+// the API version, like "live" in "v2/live/train/12138/date/05-04-2018".
+var pathClasses = map[string]EndpointClass{
+	"route":           StaticEndpoint,
+	"name-number":     StaticEndpoint,
+	"name-to-code":    StaticEndpoint,
+	"code-to-name":    StaticEndpoint,
+	"suggest-station": StaticEndpoint,
+	"suggest-train":   StaticEndpoint,
+	"between":         ScheduleEndpoint,
+	"fare":            ScheduleEndpoint,
+	"cancelled":       ScheduleEndpoint,
+	"rescheduled":     ScheduleEndpoint,
+	"live":            LiveEndpoint,
+	"arrivals":        LiveEndpoint,
+	"check-seat":      LiveEndpoint,
+	"pnr-status":      PNREndpoint,
+}
+
+// classOf returns the class of endpoint requested by 'r', using the path
+// of its request 'req' if 'r' is unknown.
+func classOf(r Requester, req *http.Request) EndpointClass {
+	if ec := ClassOf(r); ec != UnknownEndpoint || req == nil {
+		return ec
+	}
+
+	return pathClasses[pathEndpoint(req.URL)]
+}
+
+// pathEndpoint returns the path segment of 'u' following the API version,
+// like "live" in "v2/live/train/12138/date/05-04-2018".
+func pathEndpoint(u *url.URL) string {
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i < len(segs)-1; i++ {
+		if segs[i] == "v2" {
+			return segs[i+1]
+		}
+	}
+	return ""
+}
+
+var (
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
+)
+
+// httpClient returns the http.Client used to send requests.
+//
+// Clients without an HTTPClient share a private http.Client, built on first
+// use, leaving http.DefaultClient untouched.
+func (c Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+
+	defaultClientOnce.Do(func() {
+		defaultClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          100,
+				MaxIdleConnsPerHost:   16,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: time.Second,
+			},
+		}
+	})
+	return defaultClient
+}
+
+// timeout returns the timeout of each attempt to send request 'req' of 'r'.
+// Requests have no timeout other than HTTPClient's if it returns zero.
+func (c Client) timeout(r Requester, req *http.Request) time.Duration {
+	if d, ok := c.Timeouts[classOf(r, req)]; ok {
+		return d
+	}
+	if c.HTTPClient == nil {
+		return DefaultTimeout
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package rail_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-india/rail"
+	"github.com/pkg/errors"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		input    rail.Requester
+		expected rail.EndpointClass
+	}{
+		{rail.TrainRouteReq{}, rail.StaticEndpoint},
+		{rail.SuggestStationReq{}, rail.StaticEndpoint},
+		{rail.TrainFareReq{}, rail.ScheduleEndpoint},
+		{rail.CancelledTrainsReq{}, rail.ScheduleEndpoint},
+		{rail.LiveTrainStatusReq{}, rail.LiveEndpoint},
+		{rail.CheckSeatReq{}, rail.LiveEndpoint},
+		{rail.PNRStatusReq{}, rail.PNREndpoint},
+		{rail.WithCtx(context.Background(), rail.PNRStatusReq{}), rail.PNREndpoint},
+		{mockRequester(nil), rail.UnknownEndpoint},
+	}
+
+	for i, tt := range tests {
+		if actual := rail.ClassOf(tt.input); actual != tt.expected {
+			t.Errorf("%d. expected: `%s`, actual `%s`", i, tt.expected, actual)
+		}
+	}
+}
+
+func TestClientTimeouts(t *testing.T) {
+	bt := &blockingTransport{release: make(chan struct{})}
+
+	c := rail.NewClient(getAPIKey())
+	c.HTTPClient = &http.Client{Transport: bt}
+	c.Timeouts = map[rail.EndpointClass]time.Duration{rail.LiveEndpoint: 10 * time.Millisecond}
+	c.Retry = &rail.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}
+
+	_, err := c.LiveTrainStatus(context.Background(), 14311, time.Now())
+	var terr rail.ErrTimeout
+	if !errors.As(err, &terr) {
+		t.Fatal("expected ErrTimeout, got:", err)
+	}
+	if terr.Timeout != 10*time.Millisecond {
+		t.Errorf("expected: `%s`, actual `%s`", 10*time.Millisecond, terr.Timeout)
+	}
+	if bt.calls != 2 {
+		t.Errorf("expected %d calls, actual %d", 2, bt.calls)
+	}
+
+	// Cancelled context isn't a timeout and isn't retried.
+	bt.calls = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = c.LiveTrainStatus(ctx, 14311, time.Now())
+	if errors.As(err, &terr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected context.DeadlineExceeded, got:", err)
+	}
+	if bt.calls != 1 {
+		t.Errorf("expected %d calls, actual %d", 1, bt.calls)
+	}
+}
+
+func TestClientDefaultHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response_code": 200}`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	c := rail.Client{BaseURL: u, Auth: rail.NewAuth(getAPIKey())}
+	if _, err := c.TrainByNumber(context.Background(), 14311); err != nil {
+		t.Fatal("TrainByNumber failed:", err)
+	}
+
+	if http.DefaultClient.Transport != nil || http.DefaultClient.Timeout != 0 {
+		t.Error("http.DefaultClient was modified")
+	}
+}